@@ -7,11 +7,16 @@ import (
 	"github.com/supabase/cli/internal/db/branch/list"
 	"github.com/supabase/cli/internal/db/changes"
 	"github.com/supabase/cli/internal/db/commit"
+	"github.com/supabase/cli/internal/db/dump"
+	"github.com/supabase/cli/internal/db/lint"
 	"github.com/supabase/cli/internal/db/push"
 	remoteChanges "github.com/supabase/cli/internal/db/remote/changes"
 	remoteCommit "github.com/supabase/cli/internal/db/remote/commit"
 	"github.com/supabase/cli/internal/db/remote/set"
 	"github.com/supabase/cli/internal/db/reset"
+	"github.com/supabase/cli/internal/db/restore"
+	"github.com/supabase/cli/internal/db/rollback"
+	"github.com/supabase/cli/internal/db/seed"
 	"github.com/supabase/cli/internal/db/switch_"
 )
 
@@ -68,6 +73,43 @@ var (
 		},
 	}
 
+	dbDumpCmd = &cobra.Command{
+		Use:   "dump",
+		Short: "Dump the local database, or the remote database, as plain SQL. Schemas managed by Supabase are left out.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			file, err := cmd.Flags().GetString("file")
+			if err != nil {
+				return err
+			}
+			dataOnly, err := cmd.Flags().GetBool("data-only")
+			if err != nil {
+				return err
+			}
+			schemaOnly, err := cmd.Flags().GetBool("schema-only")
+			if err != nil {
+				return err
+			}
+			schemas, err := cmd.Flags().GetStringSlice("schema")
+			if err != nil {
+				return err
+			}
+			remote, err := cmd.Flags().GetBool("remote")
+			if err != nil {
+				return err
+			}
+
+			return dump.Run(file, dataOnly, schemaOnly, schemas, remote)
+		},
+	}
+
+	dbLintCmd = &cobra.Command{
+		Use:   "lint",
+		Short: "Check migrations for dangerous patterns, like table rewrites and tables without row level security. Rules are configured in [db.lint.rules].",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return lint.Run()
+		},
+	}
+
 	dryRun bool
 
 	dbPushCmd = &cobra.Command{
@@ -115,6 +157,48 @@ var (
 		},
 	}
 
+	dbRestoreCmd = &cobra.Command{
+		Use:   "restore",
+		Short: "Load a plain SQL dump into a branch, creating the branch if it doesn't exist. Schemas managed by Supabase are left out.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			file, err := cmd.Flags().GetString("file")
+			if err != nil {
+				return err
+			}
+			branch, err := cmd.Flags().GetString("branch")
+			if err != nil {
+				return err
+			}
+
+			return restore.Run(file, branch)
+		},
+	}
+
+	dbRollbackCmd = &cobra.Command{
+		Use:   "rollback",
+		Short: "Roll back applied migrations using their paired down migrations.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			to, err := cmd.Flags().GetString("to")
+			if err != nil {
+				return err
+			}
+			remote, err := cmd.Flags().GetBool("remote")
+			if err != nil {
+				return err
+			}
+
+			return rollback.Run(to, remote)
+		},
+	}
+
+	dbSeedCmd = &cobra.Command{
+		Use:   "seed",
+		Short: "Apply the seed files configured in [db.seed] to the local database, without resetting it.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return seed.Run()
+		},
+	}
+
 	dbSwitchCmd = &cobra.Command{
 		Use:   "switch <branch name>",
 		Short: "Switch branches.",
@@ -132,6 +216,13 @@ func init() {
 	dbCmd.AddCommand(dbBranchCmd)
 	dbCmd.AddCommand(dbChangesCmd)
 	dbCmd.AddCommand(dbCommitCmd)
+	dbDumpCmd.Flags().StringP("file", "f", "", "File to write the dump to. Defaults to standard output.")
+	dbDumpCmd.Flags().Bool("data-only", false, "Dump only the data, not the schema.")
+	dbDumpCmd.Flags().Bool("schema-only", false, "Dump only the schema, not the data.")
+	dbDumpCmd.Flags().StringSlice("schema", []string{}, "Comma separated list of schemas to dump. Defaults to all schemas not managed by Supabase.")
+	dbDumpCmd.Flags().Bool("remote", false, "Dump the remote database instead of the local database.")
+	dbCmd.AddCommand(dbDumpCmd)
+	dbCmd.AddCommand(dbLintCmd)
 	dbPushCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print the migrations that would be applied, but don't actually apply them.")
 	dbCmd.AddCommand(dbPushCmd)
 	dbRemoteCmd.AddCommand(dbRemoteSetCmd)
@@ -139,6 +230,14 @@ func init() {
 	dbRemoteCmd.AddCommand(dbRemoteCommitCmd)
 	dbCmd.AddCommand(dbRemoteCmd)
 	dbCmd.AddCommand(dbResetCmd)
+	dbRestoreCmd.Flags().StringP("file", "f", "", "Plain SQL dump to load.")
+	_ = dbRestoreCmd.MarkFlagRequired("file")
+	dbRestoreCmd.Flags().String("branch", "", "Branch to load the dump into. Defaults to the current branch.")
+	dbCmd.AddCommand(dbRestoreCmd)
+	dbRollbackCmd.Flags().String("to", "", "Roll back every migration after this version. Defaults to rolling back only the last migration.")
+	dbRollbackCmd.Flags().Bool("remote", false, "Roll back the remote database instead of the local database.")
+	dbCmd.AddCommand(dbRollbackCmd)
+	dbCmd.AddCommand(dbSeedCmd)
 	dbCmd.AddCommand(dbSwitchCmd)
 	rootCmd.AddCommand(dbCmd)
 }
@@ -1,11 +1,15 @@
 package cmd
 
 import (
+	"errors"
+
 	"github.com/spf13/cobra"
 	"github.com/supabase/cli/internal/functions/delete"
 	"github.com/supabase/cli/internal/functions/deploy"
+	"github.com/supabase/cli/internal/functions/list"
 	"github.com/supabase/cli/internal/functions/new"
 	"github.com/supabase/cli/internal/functions/serve"
+	"github.com/supabase/cli/internal/utils"
 )
 
 var (
@@ -29,20 +33,54 @@ var (
 	}
 
 	functionsDeployCmd = &cobra.Command{
-		Use:   "deploy <Function name>",
-		Short: "Deploy a Function to the linked Supabase project.",
-		Args:  cobra.ExactArgs(1),
+		Use:   "deploy [Function name]",
+		Short: "Deploy a Function to the linked Supabase project, or all local Functions if no name is given.",
+		Args:  cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			noVerifyJWT, err := cmd.Flags().GetBool("no-verify-jwt")
+			all, err := cmd.Flags().GetBool("all")
+			if err != nil {
+				return err
+			}
+			var slug string
+			if len(args) > 0 {
+				if all {
+					return errors.New("Cannot use both a Function name and " + utils.Aqua("--all") + ".")
+				}
+				slug = args[0]
+			}
+			// The flag overrides verify_jwt in supabase.toml only if set.
+			var verifyJWT *bool
+			if cmd.Flags().Changed("no-verify-jwt") {
+				noVerifyJWT, err := cmd.Flags().GetBool("no-verify-jwt")
+				if err != nil {
+					return err
+				}
+				x := !noVerifyJWT
+				verifyJWT = &x
+			}
+			noLint, err := cmd.Flags().GetBool("no-lint")
+			if err != nil {
+				return err
+			}
+			projectRef, err := cmd.Flags().GetString("project-ref")
 			if err != nil {
 				return err
 			}
+
+			return deploy.Run(slug, projectRef, verifyJWT, noLint)
+		},
+	}
+
+	functionsListCmd = &cobra.Command{
+		Use:   "list",
+		Short: "List all Functions in the linked Supabase project.",
+		RunE: func(cmd *cobra.Command, args []string) error {
 			projectRef, err := cmd.Flags().GetString("project-ref")
 			if err != nil {
 				return err
 			}
 
-			return deploy.Run(args[0], projectRef, !noVerifyJWT)
+			return list.Run(projectRef)
 		},
 	}
 
@@ -56,32 +94,46 @@ var (
 	}
 
 	functionsServeCmd = &cobra.Command{
-		Use:   "serve <Function name>",
-		Short: "Serve a Function locally.",
-		Args:  cobra.ExactArgs(1),
+		Use:   "serve [Function name]",
+		Short: "Serve a Function locally, or all local Functions if no name is given.",
+		Args:  cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			var slug string
+			if len(args) > 0 {
+				slug = args[0]
+			}
 			envFilePath, err := cmd.Flags().GetString("env-file")
 			if err != nil {
 				return err
 			}
-			noVerifyJWT, err := cmd.Flags().GetBool("no-verify-jwt")
-			if err != nil {
-				return err
+			// The flag overrides verify_jwt in supabase.toml only if set.
+			var verifyJWT *bool
+			if cmd.Flags().Changed("no-verify-jwt") {
+				noVerifyJWT, err := cmd.Flags().GetBool("no-verify-jwt")
+				if err != nil {
+					return err
+				}
+				x := !noVerifyJWT
+				verifyJWT = &x
 			}
 
-			return serve.Run(args[0], envFilePath, !noVerifyJWT)
+			return serve.Run(slug, envFilePath, verifyJWT)
 		},
 	}
 )
 
 func init() {
 	functionsDeleteCmd.Flags().String("project-ref", "", "Project ref of the Supabase project")
+	functionsDeployCmd.Flags().Bool("all", false, "Deploy all local Functions, skipping ones that are unchanged. Same as passing no name.")
 	functionsDeployCmd.Flags().Bool("no-verify-jwt", false, "Disable JWT verification for the Function")
+	functionsDeployCmd.Flags().Bool("no-lint", false, "Skip checking the bundle for eval() and the Function constructor")
 	functionsDeployCmd.Flags().String("project-ref", "", "Project ref of the Supabase project")
+	functionsListCmd.Flags().String("project-ref", "", "Project ref of the Supabase project")
 	functionsServeCmd.Flags().Bool("no-verify-jwt", false, "Disable JWT verification for the Function")
 	functionsServeCmd.Flags().String("env-file", "", "Path to an env file to be populated to the Function environment")
 	functionsCmd.AddCommand(functionsDeleteCmd)
 	functionsCmd.AddCommand(functionsDeployCmd)
+	functionsCmd.AddCommand(functionsListCmd)
 	functionsCmd.AddCommand(functionsNewCmd)
 	functionsCmd.AddCommand(functionsServeCmd)
 	rootCmd.AddCommand(functionsCmd)
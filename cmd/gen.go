@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/supabase/cli/internal/gen/types"
+)
+
+var (
+	genCmd = &cobra.Command{
+		Use:   "gen",
+		Short: "Generate code from the database schema.",
+	}
+
+	genTypesCmd = &cobra.Command{
+		Use:   "types --lang <typescript|go>",
+		Short: "Generate types for the tables, views, enums and functions in the local database.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			lang, err := cmd.Flags().GetString("lang")
+			if err != nil {
+				return err
+			}
+			schemas, err := cmd.Flags().GetStringSlice("schema")
+			if err != nil {
+				return err
+			}
+			dbUrl, err := cmd.Flags().GetString("db-url")
+			if err != nil {
+				return err
+			}
+			remote, err := cmd.Flags().GetBool("remote")
+			if err != nil {
+				return err
+			}
+
+			return types.Run(lang, schemas, dbUrl, remote)
+		},
+	}
+)
+
+func init() {
+	genTypesCmd.Flags().String("lang", types.LangTypescript, "Output language: "+types.LangTypescript+" or "+types.LangGo+".")
+	genTypesCmd.Flags().StringSlice("schema", []string{}, "Comma separated list of schemas to include. Defaults to public plus api.schemas in supabase.toml.")
+	genTypesCmd.Flags().String("db-url", "", "Introspect the database at this connection string instead of the local database.")
+	genTypesCmd.Flags().Bool("remote", false, "Introspect the remote database set by supabase db remote set instead of the local database.")
+	genCmd.AddCommand(genTypesCmd)
+	rootCmd.AddCommand(genCmd)
+}
@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/supabase/cli/internal/images/list"
+	"github.com/supabase/cli/internal/images/pull"
+)
+
+var (
+	imagesCmd = &cobra.Command{
+		Use:   "images",
+		Short: "Manage the Docker images used by the local development setup.",
+	}
+
+	imagesListCmd = &cobra.Command{
+		Use:   "list",
+		Short: "List the images used by the local development setup.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return list.Run()
+		},
+	}
+
+	imagesPullCmd = &cobra.Command{
+		Use:   "pull",
+		Short: "Pull the images used by the local development setup, without starting it.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return pull.Run()
+		},
+	}
+)
+
+func init() {
+	imagesCmd.AddCommand(imagesListCmd)
+	imagesCmd.AddCommand(imagesPullCmd)
+	rootCmd.AddCommand(imagesCmd)
+}
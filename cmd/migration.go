@@ -2,14 +2,17 @@ package cmd
 
 import (
 	"github.com/spf13/cobra"
+	"github.com/supabase/cli/internal/migration/baseline"
+	"github.com/supabase/cli/internal/migration/list"
 	"github.com/supabase/cli/internal/migration/new"
+	"github.com/supabase/cli/internal/migration/repair"
 	"github.com/supabase/cli/internal/utils"
 )
 
 var (
 	migrationCmd = &cobra.Command{
 		Use:   "migration",
-		Short: "Create an empty migration with the " + utils.Aqua("new") + " subcommand.",
+		Short: "Create an empty migration with the " + utils.Aqua("new") + " subcommand, or manage the remote migration history.",
 	}
 
 	migrationNewCmd = &cobra.Command{
@@ -20,9 +23,45 @@ var (
 			return new.Run(args[0])
 		},
 	}
+
+	migrationListCmd = &cobra.Command{
+		Use:   "list",
+		Short: "List local and remote migrations side by side.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return list.Run()
+		},
+	}
+
+	migrationRepairCmd = &cobra.Command{
+		Use:   "repair --status <applied|reverted> <version> ...",
+		Short: "Mark migrations as applied or reverted in the remote migration history, without running them.",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			status, err := cmd.Flags().GetString("status")
+			if err != nil {
+				return err
+			}
+
+			return repair.Run(status, args)
+		},
+	}
+
+	migrationBaselineCmd = &cobra.Command{
+		Use:   "baseline <version>",
+		Short: "Reset the remote migration history to all local migrations up to and including a version.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return baseline.Run(args[0])
+		},
+	}
 )
 
 func init() {
+	migrationRepairCmd.Flags().String("status", "", "Version status to record: "+repair.Applied+" or "+repair.Reverted+".")
+	_ = migrationRepairCmd.MarkFlagRequired("status")
+	migrationCmd.AddCommand(migrationBaselineCmd)
+	migrationCmd.AddCommand(migrationListCmd)
 	migrationCmd.AddCommand(migrationNewCmd)
+	migrationCmd.AddCommand(migrationRepairCmd)
 	rootCmd.AddCommand(migrationCmd)
 }
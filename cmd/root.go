@@ -2,6 +2,8 @@ package cmd
 
 import (
 	"github.com/spf13/cobra"
+	"github.com/supabase/cli/internal/api"
+	"github.com/supabase/cli/internal/utils"
 )
 
 // Passed from `-ldflags`: https://stackoverflow.com/q/11354518.
@@ -21,4 +23,7 @@ func Execute() {
 
 func init() {
 	rootCmd.SetVersionTemplate("{{.Version}}\n")
+	rootCmd.PersistentFlags().StringVar(&api.BaseUrlFlag, "api-url", "", "Management API base URL. Defaults to $SUPABASE_API_URL or "+api.DefaultBaseUrl+".")
+	rootCmd.PersistentFlags().StringVar(&utils.RegistryFlag, "registry", "", "Registry to pull Docker images from, e.g. a mirror. Defaults to $SUPABASE_REGISTRY or "+utils.DefaultRegistry+".")
+	rootCmd.PersistentFlags().VarP(&utils.OutputFormat, "output", "o", "Output format of status, secrets, branches, Functions, images and lint results.")
 }
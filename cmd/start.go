@@ -1,6 +1,8 @@
 package cmd
 
 import (
+	"time"
+
 	"github.com/spf13/cobra"
 	"github.com/supabase/cli/internal/start"
 )
@@ -9,10 +11,27 @@ var startCmd = &cobra.Command{
 	Use:   "start",
 	Short: "Start the Supabase local development setup.",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return start.Run()
+		exclude, err := cmd.Flags().GetStringSlice("exclude")
+		if err != nil {
+			return err
+		}
+		only, err := cmd.Flags().GetStringSlice("only")
+		if err != nil {
+			return err
+		}
+
+		timeout, err := cmd.Flags().GetDuration("timeout")
+		if err != nil {
+			return err
+		}
+
+		return start.Run(exclude, only, timeout)
 	},
 }
 
 func init() {
+	startCmd.Flags().StringSlice("exclude", []string{}, "Comma separated list of services not to start, e.g. studio,realtime. The database is always started.")
+	startCmd.Flags().StringSlice("only", []string{}, "Comma separated list of services to start alongside the database, ignoring enabled in supabase.toml.")
+	startCmd.Flags().Duration("timeout", 2*time.Minute, "How long to wait for all services to be healthy. 0 returns as soon as containers are started.")
 	rootCmd.AddCommand(startCmd)
 }
@@ -9,10 +9,16 @@ var stopCmd = &cobra.Command{
 	Use:   "stop",
 	Short: "Stop the Supabase local development setup.",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return stop.Run()
+		noBackup, err := cmd.Flags().GetBool("no-backup")
+		if err != nil {
+			return err
+		}
+
+		return stop.Run(!noBackup)
 	},
 }
 
 func init() {
+	stopCmd.Flags().Bool("no-backup", false, "Delete all branches instead of backing them up.")
 	rootCmd.AddCommand(stopCmd)
 }
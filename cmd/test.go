@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	testDb "github.com/supabase/cli/internal/test/db"
+)
+
+var (
+	testCmd = &cobra.Command{
+		Use:   "test",
+		Short: "Run tests on the local stack.",
+	}
+
+	testDbCmd = &cobra.Command{
+		Use:   "db",
+		Short: "Run the pgTAP tests in .supabase/tests on a shadow database built from migrations. Each test file runs in a transaction that is rolled back.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			junit, err := cmd.Flags().GetString("junit")
+			if err != nil {
+				return err
+			}
+
+			return testDb.Run(junit)
+		},
+	}
+)
+
+func init() {
+	testDbCmd.Flags().String("junit", "", "Also write the results as JUnit XML to this file.")
+	testCmd.AddCommand(testDbCmd)
+	rootCmd.AddCommand(testCmd)
+}
@@ -0,0 +1,179 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/supabase/cli/internal/utils"
+)
+
+const (
+	DefaultBaseUrl = "https://api.supabase.io"
+	DefaultTimeout = 60 * time.Second
+	// Number of times a request is retried on 429, or 5xx for idempotent
+	// methods.
+	DefaultMaxRetries = 3
+)
+
+// Set from the global `--api-url` flag. Takes precedence over the
+// SUPABASE_API_URL environment variable.
+var BaseUrlFlag string
+
+// GetBaseUrl returns the Management API base URL, without a trailing slash.
+func GetBaseUrl() string {
+	if BaseUrlFlag != "" {
+		return strings.TrimSuffix(BaseUrlFlag, "/")
+	}
+	if baseUrl := os.Getenv("SUPABASE_API_URL"); baseUrl != "" {
+		return strings.TrimSuffix(baseUrl, "/")
+	}
+	return DefaultBaseUrl
+}
+
+// A typed client for the Supabase Management API.
+type Client struct {
+	BaseUrl     string
+	AccessToken string
+	HttpClient  *http.Client
+	MaxRetries  int
+	// Base delay between retries, doubled on every attempt.
+	Backoff time.Duration
+}
+
+func NewClient(baseUrl string, accessToken string) *Client {
+	return &Client{
+		BaseUrl:     strings.TrimSuffix(baseUrl, "/"),
+		AccessToken: accessToken,
+		HttpClient:  &http.Client{Timeout: DefaultTimeout},
+		MaxRetries:  DefaultMaxRetries,
+		Backoff:     500 * time.Millisecond,
+	}
+}
+
+// NewDefaultClient returns a client for the configured base URL, authenticated
+// with the access token from `supabase login` or SUPABASE_ACCESS_TOKEN.
+func NewDefaultClient() (*Client, error) {
+	accessToken, err := utils.LoadAccessToken()
+	if err != nil {
+		return nil, err
+	}
+
+	return NewClient(GetBaseUrl(), accessToken), nil
+}
+
+// An error response from the Management API.
+type Error struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *Error) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("%d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	}
+	return e.Message
+}
+
+func IsNotFound(err error) bool {
+	var apiErr *Error
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+// shouldRetry reports whether a response warrants another attempt. A 429 means
+// the request wasn't processed, but after a 5xx a POST or PATCH may have taken
+// effect, e.g. a Function created before the gateway timed out, so only
+// idempotent methods are retried then.
+func shouldRetry(method string, statusCode int) bool {
+	if statusCode == http.StatusTooManyRequests {
+		return true
+	}
+	idempotent := method != http.MethodPost && method != http.MethodPatch
+	return idempotent && statusCode >= 500
+}
+
+// do sends a JSON request to path (relative to the base URL) and decodes the
+// JSON response into out, if non-nil. A *[]byte out receives the raw response
+// instead. Requests are retried with exponential backoff on 429 responses,
+// and on 5xx responses unless they're POST or PATCH.
+func (c *Client) do(ctx context.Context, method string, path string, in interface{}, out interface{}) error {
+	var reqBody []byte
+	if in != nil {
+		b, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		reqBody = b
+	}
+
+	for attempt := 0; ; attempt++ {
+		var body io.Reader
+		if reqBody != nil {
+			body = bytes.NewReader(reqBody)
+		}
+		req, err := http.NewRequestWithContext(ctx, method, c.BaseUrl+path, body)
+		if err != nil {
+			return err
+		}
+		req.Header.Add("Authorization", "Bearer "+c.AccessToken)
+		if reqBody != nil {
+			req.Header.Add("Content-Type", "application/json")
+		}
+
+		resp, err := c.HttpClient.Do(req)
+		if err != nil {
+			return err
+		}
+		respBody, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return err
+		}
+
+		if shouldRetry(method, resp.StatusCode) && attempt < c.MaxRetries {
+			delay := c.Backoff << attempt
+			if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+				delay = time.Duration(seconds) * time.Second
+			}
+
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(delay):
+			}
+			continue
+		}
+
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			return newError(resp.StatusCode, respBody)
+		}
+
+		if raw, ok := out.(*[]byte); ok {
+			*raw = respBody
+			return nil
+		}
+		if out == nil || len(respBody) == 0 {
+			return nil
+		}
+		return json.Unmarshal(respBody, out)
+	}
+}
+
+func newError(statusCode int, body []byte) *Error {
+	var data struct {
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(body, &data); err == nil && data.Message != "" {
+		return &Error{StatusCode: statusCode, Message: data.Message}
+	}
+
+	return &Error{StatusCode: statusCode, Message: strings.TrimSpace(string(body))}
+}
@@ -0,0 +1,102 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestClient(t *testing.T) {
+	t.Run("retries on 5xx with backoff", func(t *testing.T) {
+		var calls int
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			if r.Header.Get("Authorization") != "Bearer token" {
+				t.Errorf("unexpected Authorization header: %s", r.Header.Get("Authorization"))
+			}
+			if calls < 3 {
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			_ = json.NewEncoder(w).Encode([]Secret{{Name: "FOO", Value: "bar"}})
+		}))
+		defer server.Close()
+
+		client := NewClient(server.URL, "token")
+		client.Backoff = time.Millisecond
+		secrets, err := client.ListSecrets(context.Background(), "abcdefghijklmnopqrst")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if calls != 3 {
+			t.Errorf("expected 3 calls, got %d", calls)
+		}
+		if len(secrets) != 1 || secrets[0].Name != "FOO" {
+			t.Errorf("unexpected secrets: %+v", secrets)
+		}
+	})
+
+	t.Run("retries POST only on 429", func(t *testing.T) {
+		var calls int
+		status := http.StatusBadGateway
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			if calls == 1 {
+				w.WriteHeader(status)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(Function{Slug: "hello"})
+		}))
+		defer server.Close()
+
+		client := NewClient(server.URL, "token")
+		client.Backoff = time.Millisecond
+		if _, err := client.CreateFunction(context.Background(), "abcdefghijklmnopqrst", CreateFunctionRequest{Slug: "hello"}); err == nil {
+			t.Error("expected the 5xx to be returned")
+		}
+		if calls != 1 {
+			t.Errorf("expected 1 call, got %d", calls)
+		}
+
+		calls = 0
+		status = http.StatusTooManyRequests
+		if _, err := client.CreateFunction(context.Background(), "abcdefghijklmnopqrst", CreateFunctionRequest{Slug: "hello"}); err != nil {
+			t.Fatal(err)
+		}
+		if calls != 2 {
+			t.Errorf("expected 2 calls, got %d", calls)
+		}
+	})
+
+	t.Run("returns structured error", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message":"Function not found"}`))
+		}))
+		defer server.Close()
+
+		client := NewClient(server.URL, "token")
+		_, err := client.GetFunction(context.Background(), "abcdefghijklmnopqrst", "hello")
+		if !IsNotFound(err) {
+			t.Fatalf("expected not found error, got %v", err)
+		}
+		if err.Error() != "Function not found" {
+			t.Errorf("unexpected error message: %s", err)
+		}
+	})
+
+	t.Run("base URL precedence", func(t *testing.T) {
+		t.Setenv("SUPABASE_API_URL", "http://localhost:8080/")
+		if GetBaseUrl() != "http://localhost:8080" {
+			t.Errorf("unexpected base URL: %s", GetBaseUrl())
+		}
+		BaseUrlFlag = "http://127.0.0.1:9999"
+		t.Cleanup(func() { BaseUrlFlag = "" })
+		if GetBaseUrl() != "http://127.0.0.1:9999" {
+			t.Errorf("unexpected base URL: %s", GetBaseUrl())
+		}
+	})
+}
@@ -0,0 +1,74 @@
+package api
+
+import (
+	"context"
+	"net/http"
+)
+
+type Function struct {
+	Id        string `json:"id"`
+	Slug      string `json:"slug"`
+	Name      string `json:"name"`
+	Status    string `json:"status"`
+	Version   int    `json:"version"`
+	VerifyJwt bool   `json:"verify_jwt"`
+	CreatedAt int64  `json:"created_at"`
+	UpdatedAt int64  `json:"updated_at"`
+}
+
+type CreateFunctionRequest struct {
+	Slug      string `json:"slug"`
+	Name      string `json:"name"`
+	Body      string `json:"body"`
+	VerifyJwt bool   `json:"verify_jwt"`
+}
+
+type UpdateFunctionRequest struct {
+	Body      string `json:"body"`
+	VerifyJwt bool   `json:"verify_jwt"`
+}
+
+func (c *Client) ListFunctions(ctx context.Context, projectRef string) ([]Function, error) {
+	var functions []Function
+	if err := c.do(ctx, http.MethodGet, "/v1/projects/"+projectRef+"/functions", nil, &functions); err != nil {
+		return nil, err
+	}
+	return functions, nil
+}
+
+func (c *Client) GetFunction(ctx context.Context, projectRef string, slug string) (*Function, error) {
+	var function Function
+	if err := c.do(ctx, http.MethodGet, "/v1/projects/"+projectRef+"/functions/"+slug, nil, &function); err != nil {
+		return nil, err
+	}
+	return &function, nil
+}
+
+// GetFunctionBody returns the body of the deployed Function, as uploaded.
+func (c *Client) GetFunctionBody(ctx context.Context, projectRef string, slug string) ([]byte, error) {
+	var body []byte
+	if err := c.do(ctx, http.MethodGet, "/v1/projects/"+projectRef+"/functions/"+slug+"/body", nil, &body); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+func (c *Client) CreateFunction(ctx context.Context, projectRef string, body CreateFunctionRequest) (*Function, error) {
+	var function Function
+	if err := c.do(ctx, http.MethodPost, "/v1/projects/"+projectRef+"/functions", body, &function); err != nil {
+		return nil, err
+	}
+	return &function, nil
+}
+
+func (c *Client) UpdateFunction(ctx context.Context, projectRef string, slug string, body UpdateFunctionRequest) (*Function, error) {
+	var function Function
+	if err := c.do(ctx, http.MethodPatch, "/v1/projects/"+projectRef+"/functions/"+slug, body, &function); err != nil {
+		return nil, err
+	}
+	return &function, nil
+}
+
+func (c *Client) DeleteFunction(ctx context.Context, projectRef string, slug string) error {
+	return c.do(ctx, http.MethodDelete, "/v1/projects/"+projectRef+"/functions/"+slug, nil, nil)
+}
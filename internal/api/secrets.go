@@ -0,0 +1,27 @@
+package api
+
+import (
+	"context"
+	"net/http"
+)
+
+type Secret struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+func (c *Client) ListSecrets(ctx context.Context, projectRef string) ([]Secret, error) {
+	var secrets []Secret
+	if err := c.do(ctx, http.MethodGet, "/v1/projects/"+projectRef+"/secrets", nil, &secrets); err != nil {
+		return nil, err
+	}
+	return secrets, nil
+}
+
+func (c *Client) CreateSecrets(ctx context.Context, projectRef string, secrets []Secret) error {
+	return c.do(ctx, http.MethodPost, "/v1/projects/"+projectRef+"/secrets", secrets, nil)
+}
+
+func (c *Client) DeleteSecrets(ctx context.Context, projectRef string, names []string) error {
+	return c.do(ctx, http.MethodDelete, "/v1/projects/"+projectRef+"/secrets", names, nil)
+}
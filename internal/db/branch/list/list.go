@@ -8,9 +8,17 @@ import (
 	"github.com/supabase/cli/internal/utils"
 )
 
+type branchEntry struct {
+	Name    string `json:"name"`
+	Current bool   `json:"current"`
+}
+
 func Run() error {
 	branches, err := os.ReadDir(".supabase/branches")
 	if errors.Is(err, os.ErrNotExist) {
+		if utils.OutputFormat.Value != utils.OutputPretty {
+			return utils.EncodeOutput(utils.OutputFormat.Value, os.Stdout, []branchEntry{})
+		}
 		return nil
 	} else if err != nil {
 		return err
@@ -21,6 +29,17 @@ func Run() error {
 		return err
 	}
 
+	if utils.OutputFormat.Value != utils.OutputPretty {
+		entries := []branchEntry{}
+		for _, branch := range branches {
+			if branch.Name() == "_current_branch" {
+				continue
+			}
+			entries = append(entries, branchEntry{Name: branch.Name(), Current: branch.Name() == currBranch})
+		}
+		return utils.EncodeOutput(utils.OutputFormat.Value, os.Stdout, entries)
+	}
+
 	for _, branch := range branches {
 		if branch.Name() == "_current_branch" {
 			continue
@@ -0,0 +1,199 @@
+package lint
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/supabase/cli/internal/utils"
+)
+
+// Run lints the migrations in `.supabase/migrations` and prints the findings.
+// Down migrations are skipped, since dropping things is what they're for. It
+// fails if any finding has severity `error`, so it can gate CI.
+func Run() error {
+	// Sanity checks.
+	{
+		if err := utils.LoadConfig(); err != nil {
+			return err
+		}
+	}
+
+	severities, err := Severities(utils.Config.Db.Lint.Rules)
+	if err != nil {
+		return err
+	}
+
+	if err := utils.MkdirIfNotExist(".supabase/migrations"); err != nil {
+		return err
+	}
+	migrations, err := utils.ReadMigrationsDir()
+	if err != nil {
+		return err
+	}
+
+	files := []File{}
+	for _, migration := range migrations {
+		path := filepath.Join(".supabase", "migrations", migration.Name())
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		files = append(files, File{Path: path, Sql: string(content)})
+	}
+
+	findings := Lint(files, severities)
+
+	switch utils.OutputFormat.Value {
+	case utils.OutputPretty:
+		printFindings(os.Stdout, findings)
+	case utils.OutputSarif:
+		if err := encodeSarif(os.Stdout, findings, severities); err != nil {
+			return err
+		}
+	default:
+		if err := utils.EncodeOutput(utils.OutputFormat.Value, os.Stdout, findings); err != nil {
+			return err
+		}
+	}
+
+	errorCount := 0
+	for _, finding := range findings {
+		if finding.Severity == SeverityError {
+			errorCount++
+		}
+	}
+	if errorCount > 0 {
+		return fmt.Errorf("Found %d lint errors in %s.", errorCount, utils.Bold(".supabase/migrations"))
+	}
+
+	return nil
+}
+
+func printFindings(w io.Writer, findings []Finding) {
+	if len(findings) == 0 {
+		fmt.Fprintln(w, "No issues found in "+utils.Bold(".supabase/migrations")+".")
+		return
+	}
+
+	for _, finding := range findings {
+		severity := finding.Severity
+		if severity == SeverityError {
+			severity = utils.Red(severity)
+		} else {
+			severity = utils.Yellow(severity)
+		}
+		fmt.Fprintf(w, "%s:%d: %s %s [%s]\n", finding.File, finding.Line, severity, finding.Message, finding.Rule)
+	}
+}
+
+// https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html
+type (
+	sarifLog struct {
+		Schema  string     `json:"$schema"`
+		Version string     `json:"version"`
+		Runs    []sarifRun `json:"runs"`
+	}
+
+	sarifRun struct {
+		Tool    sarifTool     `json:"tool"`
+		Results []sarifResult `json:"results"`
+	}
+
+	sarifTool struct {
+		Driver sarifDriver `json:"driver"`
+	}
+
+	sarifDriver struct {
+		Name           string      `json:"name"`
+		InformationUri string      `json:"informationUri"`
+		Rules          []sarifRule `json:"rules"`
+	}
+
+	sarifRule struct {
+		Id                   string            `json:"id"`
+		ShortDescription     sarifMessage      `json:"shortDescription"`
+		DefaultConfiguration sarifRuleDefaults `json:"defaultConfiguration"`
+	}
+
+	sarifRuleDefaults struct {
+		Level string `json:"level"`
+	}
+
+	sarifMessage struct {
+		Text string `json:"text"`
+	}
+
+	sarifResult struct {
+		RuleId    string          `json:"ruleId"`
+		Level     string          `json:"level"`
+		Message   sarifMessage    `json:"message"`
+		Locations []sarifLocation `json:"locations"`
+	}
+
+	sarifLocation struct {
+		PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+	}
+
+	sarifPhysicalLocation struct {
+		ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+		Region           sarifRegion           `json:"region"`
+	}
+
+	sarifArtifactLocation struct {
+		Uri string `json:"uri"`
+	}
+
+	sarifRegion struct {
+		StartLine int `json:"startLine"`
+	}
+)
+
+func sarifLevel(severity string) string {
+	switch severity {
+	case SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	default:
+		return "none"
+	}
+}
+
+func encodeSarif(w io.Writer, findings []Finding, severities map[string]string) error {
+	driver := sarifDriver{
+		Name:           "supabase db lint",
+		InformationUri: "https://github.com/supabase/cli",
+		Rules:          []sarifRule{},
+	}
+	for _, rule := range Rules {
+		driver.Rules = append(driver.Rules, sarifRule{
+			Id:                   rule.Id,
+			ShortDescription:     sarifMessage{Text: rule.Description},
+			DefaultConfiguration: sarifRuleDefaults{Level: sarifLevel(severities[rule.Id])},
+		})
+	}
+
+	results := []sarifResult{}
+	for _, finding := range findings {
+		results = append(results, sarifResult{
+			RuleId:  finding.Rule,
+			Level:   sarifLevel(finding.Severity),
+			Message: sarifMessage{Text: finding.Message},
+			Locations: []sarifLocation{{PhysicalLocation: sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{Uri: filepath.ToSlash(finding.File)},
+				Region:           sarifRegion{StartLine: finding.Line},
+			}}},
+		})
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{{Tool: sarifTool{Driver: driver}, Results: results}},
+	})
+}
@@ -0,0 +1,205 @@
+package lint
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/supabase/cli/internal/migration"
+	"github.com/supabase/cli/internal/utils"
+)
+
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+	SeverityOff     = "off"
+)
+
+type Rule struct {
+	Id          string
+	Description string
+	// Default severity.
+	Severity string
+}
+
+// Rules are listed in the order they're documented. Their severity can be
+// overridden in `[db.lint.rules]`.
+var Rules = []Rule{
+	{Id: "index-not-concurrent", Severity: SeverityWarning, Description: "CREATE INDEX without CONCURRENTLY blocks writes to the table while the index builds. Migrations with CONCURRENTLY are applied one statement at a time, outside a transaction."},
+	{Id: "alter-column-type", Severity: SeverityWarning, Description: "ALTER COLUMN TYPE rewrites the table and its indexes under an ACCESS EXCLUSIVE lock."},
+	{Id: "not-null-without-default", Severity: SeverityError, Description: "Adding a NOT NULL column without a DEFAULT fails on tables with existing rows."},
+	{Id: "drop-column", Severity: SeverityWarning, Description: "Dropping a column breaks application code that still reads it."},
+	{Id: "drop-table", Severity: SeverityWarning, Description: "Dropping a table breaks application code that still reads it, and loses its data."},
+	{Id: "missing-rls", Severity: SeverityError, Description: "Tables in the public schema are exposed through the API unless row level security is enabled."},
+	{Id: "security-definer-search-path", Severity: SeverityError, Description: "SECURITY DEFINER functions without a fixed search_path can be hijacked by objects in other schemas."},
+}
+
+// Severities returns the severity of each rule, with the defaults overridden
+// by overrides.
+func Severities(overrides map[string]string) (map[string]string, error) {
+	severities := map[string]string{}
+	for _, rule := range Rules {
+		severities[rule.Id] = rule.Severity
+	}
+	for id, severity := range overrides {
+		if _, ok := severities[id]; !ok {
+			return nil, errors.New("Unknown lint rule in config: " + utils.Aqua("db.lint.rules."+id) + ".")
+		}
+		severities[id] = severity
+	}
+	return severities, nil
+}
+
+type Finding struct {
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Message  string `json:"message"`
+}
+
+type File struct {
+	Path string
+	Sql  string
+}
+
+var (
+	createTablePattern    = regexp.MustCompile(`(?i)^CREATE\s+(?:(?:GLOBAL\s+|LOCAL\s+)?(?:TEMP|TEMPORARY)\s+|UNLOGGED\s+)?TABLE\s+(?:IF\s+NOT\s+EXISTS\s+)?(\S+?)(?:\s|\(|$)`)
+	createTempPattern     = regexp.MustCompile(`(?i)^CREATE\s+(?:GLOBAL\s+|LOCAL\s+)?(?:TEMP|TEMPORARY)\s`)
+	createIndexPattern    = regexp.MustCompile(`(?i)^CREATE\s+(?:UNIQUE\s+)?INDEX\s+(CONCURRENTLY\s+)?(?:.*?\s)?ON\s+(?:ONLY\s+)?(\S+?)(?:\s|\(|$)`)
+	alterTablePattern     = regexp.MustCompile(`(?i)^ALTER\s+TABLE\s+(?:IF\s+EXISTS\s+)?(?:ONLY\s+)?(\S+)\s+(.*)$`)
+	dropTablePattern      = regexp.MustCompile(`(?i)^DROP\s+TABLE\s+(?:IF\s+EXISTS\s+)?(.*?)(?:\s+(?:CASCADE|RESTRICT))?\s*;?$`)
+	createFunctionPattern = regexp.MustCompile(`(?i)^CREATE\s+(?:OR\s+REPLACE\s+)?(?:FUNCTION|PROCEDURE)\s+(\S+?)\s*\(`)
+	alterTypePattern      = regexp.MustCompile(`(?i)^ALTER\s+(?:COLUMN\s+)?(\S+)\s+(?:SET\s+DATA\s+)?TYPE\s`)
+	dropColumnPattern     = regexp.MustCompile(`(?i)^DROP\s+(?:COLUMN\s+)?(?:IF\s+EXISTS\s+)?(\S+)`)
+	addColumnPattern      = regexp.MustCompile(`(?i)^ADD\s+(?:COLUMN\s+)?(?:IF\s+NOT\s+EXISTS\s+)?(\S+)\s`)
+	addConstraintPattern  = regexp.MustCompile(`(?i)^ADD\s+(?:CONSTRAINT|PRIMARY|UNIQUE|FOREIGN|CHECK|EXCLUDE)\s`)
+	notNullPattern        = regexp.MustCompile(`(?i)\bNOT\s+NULL\b`)
+	defaultPattern        = regexp.MustCompile(`(?i)\b(?:DEFAULT|GENERATED)\b`)
+	enableRlsPattern      = regexp.MustCompile(`(?i)^ENABLE\s+ROW\s+LEVEL\s+SECURITY$`)
+	securityDefinerRegexp = regexp.MustCompile(`(?i)\bSECURITY\s+DEFINER\b`)
+	searchPathPattern     = regexp.MustCompile(`(?i)\bSET\s+search_path\b`)
+)
+
+// Lint checks migration files, in the order they're applied, against the
+// rules whose severity isn't `off`. Tables created in the same file are
+// exempt from the locking rules since they have no rows yet.
+func Lint(files []File, severities map[string]string) []Finding {
+	findings := []Finding{}
+	report := func(rule string, file string, line int, message string) {
+		if severity := severities[rule]; severity != SeverityOff {
+			findings = append(findings, Finding{Rule: rule, Severity: severity, File: file, Line: line, Message: message})
+		}
+	}
+
+	// Public tables without RLS so far, to where they were created.
+	type location struct {
+		file string
+		line int
+	}
+	withoutRls := map[string]location{}
+	withoutRlsOrder := []string{}
+
+	for _, file := range files {
+		createdHere := map[string]bool{}
+
+		for _, stmt := range migration.Split(file.Sql) {
+			sql := migration.Normalize(stmt.Sql)
+
+			if matches := createTablePattern.FindStringSubmatch(sql); matches != nil {
+				table := qualify(matches[1])
+				createdHere[table] = true
+				if strings.HasPrefix(table, "public.") && !createTempPattern.MatchString(sql) {
+					if _, ok := withoutRls[table]; !ok {
+						withoutRlsOrder = append(withoutRlsOrder, table)
+					}
+					withoutRls[table] = location{file.Path, stmt.Line}
+				}
+			} else if matches := createIndexPattern.FindStringSubmatch(sql); matches != nil {
+				if matches[1] == "" && !createdHere[qualify(matches[2])] {
+					report("index-not-concurrent", file.Path, stmt.Line, "Index on "+matches[2]+" is created without CONCURRENTLY, which blocks writes to the table while the index builds.")
+				}
+			} else if matches := alterTablePattern.FindStringSubmatch(sql); matches != nil {
+				table := qualify(matches[1])
+				for _, action := range splitTopLevel(matches[2]) {
+					if enableRlsPattern.MatchString(action) {
+						delete(withoutRls, table)
+						continue
+					}
+					if createdHere[table] {
+						continue
+					}
+					if m := alterTypePattern.FindStringSubmatch(action); m != nil {
+						report("alter-column-type", file.Path, stmt.Line, "Changing the type of "+matches[1]+"."+m[1]+" may rewrite the table under an ACCESS EXCLUSIVE lock.")
+					} else if m := dropColumnPattern.FindStringSubmatch(action); m != nil && !strings.EqualFold(m[1], "CONSTRAINT") {
+						report("drop-column", file.Path, stmt.Line, "Column "+matches[1]+"."+m[1]+" is dropped. Make sure no application code still reads it.")
+					} else if m := addColumnPattern.FindStringSubmatch(action); m != nil && !addConstraintPattern.MatchString(action) {
+						if notNullPattern.MatchString(action) && !defaultPattern.MatchString(action) {
+							report("not-null-without-default", file.Path, stmt.Line, "Column "+matches[1]+"."+m[1]+" is NOT NULL without a DEFAULT, which fails if the table has rows.")
+						}
+					}
+				}
+			} else if matches := dropTablePattern.FindStringSubmatch(sql); matches != nil {
+				for _, name := range splitTopLevel(matches[1]) {
+					table := qualify(name)
+					delete(withoutRls, table)
+					if !createdHere[table] {
+						report("drop-table", file.Path, stmt.Line, "Table "+name+" is dropped. Make sure no application code still reads it.")
+					}
+				}
+			} else if matches := createFunctionPattern.FindStringSubmatch(sql); matches != nil {
+				if securityDefinerRegexp.MatchString(sql) && !searchPathPattern.MatchString(sql) {
+					report("security-definer-search-path", file.Path, stmt.Line, "SECURITY DEFINER function "+matches[1]+" doesn't set search_path. Add e.g. `SET search_path = ''`.")
+				}
+			}
+		}
+	}
+
+	for _, table := range withoutRlsOrder {
+		if loc, ok := withoutRls[table]; ok {
+			report("missing-rls", loc.file, loc.line, "Table "+table+" is created in the public schema without enabling row level security.")
+		}
+	}
+
+	return findings
+}
+
+// splitTopLevel splits s on commas outside parentheses and quotes, trimming
+// the parts and any trailing semicolon.
+func splitTopLevel(s string) []string {
+	s = strings.TrimSuffix(strings.TrimSpace(s), ";")
+	parts := []string{}
+	depth, quoted, start := 0, false, 0
+	for i, c := range s {
+		switch {
+		case c == '"':
+			quoted = !quoted
+		case quoted:
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case c == ',' && depth == 0:
+			parts = append(parts, strings.TrimSpace(s[start:i]))
+			start = i + 1
+		}
+	}
+	return append(parts, strings.TrimSpace(s[start:]))
+}
+
+// qualify returns the schema-qualified, case-folded name of a table, so that
+// `Foo`, `public.foo` and `"public"."foo"` compare equal.
+func qualify(name string) string {
+	parts := []string{}
+	for _, part := range strings.Split(name, ".") {
+		if strings.HasPrefix(part, `"`) && strings.HasSuffix(part, `"`) && len(part) > 1 {
+			parts = append(parts, strings.ReplaceAll(part[1:len(part)-1], `""`, `"`))
+		} else {
+			parts = append(parts, strings.ToLower(part))
+		}
+	}
+	if len(parts) == 1 {
+		parts = append([]string{"public"}, parts...)
+	}
+	return strings.Join(parts, ".")
+}
@@ -0,0 +1,98 @@
+package lint
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func lintSql(t *testing.T, overrides map[string]string, sqls ...string) []string {
+	severities, err := Severities(overrides)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files := []File{}
+	for i, sql := range sqls {
+		files = append(files, File{Path: fmt.Sprintf("%d.sql", i), Sql: sql})
+	}
+
+	result := []string{}
+	for _, finding := range Lint(files, severities) {
+		result = append(result, fmt.Sprintf("%s:%d %s %s", finding.File, finding.Line, finding.Severity, finding.Rule))
+	}
+	return result
+}
+
+func assertFindings(t *testing.T, actual []string, expected ...string) {
+	if strings.Join(actual, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("unexpected findings:\n%s\n\nexpected:\n%s", strings.Join(actual, "\n"), strings.Join(expected, "\n"))
+	}
+}
+
+func TestLint(t *testing.T) {
+	t.Run("new tables are exempt from locking rules", func(t *testing.T) {
+		assertFindings(t, lintSql(t, nil, `
+CREATE TABLE public.posts (id bigint);
+ALTER TABLE public.posts ENABLE ROW LEVEL SECURITY;
+CREATE INDEX posts_idx ON posts (id);
+ALTER TABLE posts ADD COLUMN title text NOT NULL, ALTER COLUMN id TYPE int;
+`))
+	})
+
+	t.Run("flags dangerous changes to existing tables", func(t *testing.T) {
+		assertFindings(t, lintSql(t, nil,
+			"CREATE TABLE posts (id bigint);\nALTER TABLE posts ENABLE ROW LEVEL SECURITY;",
+			`CREATE INDEX posts_idx ON public.posts (id);
+CREATE INDEX CONCURRENTLY posts_idx2 ON public.posts (id);
+ALTER TABLE posts
+  ADD COLUMN title text NOT NULL,
+  ADD COLUMN body text NOT NULL DEFAULT '',
+  ADD CONSTRAINT posts_check CHECK (title IS NOT NULL),
+  ALTER COLUMN id TYPE int,
+  DROP CONSTRAINT posts_pkey,
+  DROP COLUMN legacy;
+-- DROP TABLE posts;
+DROP TABLE IF EXISTS "public"."posts" CASCADE;
+`),
+			"1.sql:1 warning index-not-concurrent",
+			"1.sql:3 error not-null-without-default",
+			"1.sql:3 warning alter-column-type",
+			"1.sql:3 warning drop-column",
+			"1.sql:11 warning drop-table",
+		)
+	})
+
+	t.Run("flags public tables without row level security", func(t *testing.T) {
+		assertFindings(t, lintSql(t, nil,
+			"CREATE TABLE a (id int);\nCREATE TABLE private.b (id int);\nCREATE TABLE \"C\" (id int);",
+			"ALTER TABLE public.\"C\" ENABLE ROW LEVEL SECURITY;",
+		),
+			"0.sql:1 error missing-rls",
+		)
+	})
+
+	t.Run("flags security definer functions without search_path", func(t *testing.T) {
+		assertFindings(t, lintSql(t, nil, `
+CREATE FUNCTION a() RETURNS void LANGUAGE sql SECURITY DEFINER AS $$ SELECT 1 $$;
+CREATE FUNCTION b() RETURNS void LANGUAGE sql SECURITY DEFINER SET search_path = '' AS $$ SELECT 1 $$;
+CREATE FUNCTION c() RETURNS void LANGUAGE plpgsql AS $$ BEGIN EXECUTE 'SECURITY DEFINER'; END $$;
+`),
+			"0.sql:2 error security-definer-search-path",
+		)
+	})
+
+	t.Run("applies configured severities", func(t *testing.T) {
+		assertFindings(t, lintSql(t, map[string]string{"drop-table": "error", "drop-column": "off"},
+			"ALTER TABLE posts DROP COLUMN legacy;\nDROP TABLE posts;",
+		),
+			"0.sql:2 error drop-table",
+		)
+	})
+
+	t.Run("rejects unknown rules", func(t *testing.T) {
+		if _, err := Severities(map[string]string{"no-such-rule": "error"}); err == nil {
+			t.Error("expected to fail")
+		}
+	})
+}
@@ -5,9 +5,9 @@ import (
 	"errors"
 	"fmt"
 	"os"
-	"regexp"
 
 	pgx "github.com/jackc/pgx/v4"
+	"github.com/supabase/cli/internal/migration"
 	"github.com/supabase/cli/internal/utils"
 )
 
@@ -17,13 +17,10 @@ func Run(dryRun bool) error {
 	if dryRun {
 		fmt.Println("DRY RUN: migrations will *not* be pushed to the database.")
 	}
-	urlBytes, err := os.ReadFile(".supabase/temp/remote-db-url")
-	if errors.Is(err, os.ErrNotExist) {
-		return errors.New("Remote database is not set. Run " + utils.Aqua("supabase db remote set") + " first.")
-	} else if err != nil {
+	url, err := utils.LoadRemoteDbUrl()
+	if err != nil {
 		return err
 	}
-	url := string(urlBytes)
 
 	conn, err := pgx.Connect(ctx, url)
 	if err != nil {
@@ -33,44 +30,38 @@ func Run(dryRun bool) error {
 
 	// `schema_migrations` must be a "prefix" of `.supabase/migrations`.
 
-	rows, err := conn.Query(ctx, "SELECT version FROM supabase_migrations.schema_migrations ORDER BY version")
+	versions, err := utils.GetMigrationHistory(ctx, conn)
 	if err != nil {
 		return fmt.Errorf(`Error querying remote database: %w.
 Try running `+utils.Aqua("supabase db remote set")+".", err)
 	}
 
-	versions := []string{}
-	for rows.Next() {
-		var version string
-		if err := rows.Scan(&version); err != nil {
-			return err
-		}
-		versions = append(versions, version)
-	}
-
 	if err := utils.MkdirIfNotExist(".supabase/migrations"); err != nil {
 		return err
 	}
-	migrations, err := os.ReadDir(".supabase/migrations")
+	migrations, err := utils.ReadMigrationsDir()
 	if err != nil {
 		return err
 	}
 
-	conflictErr := errors.New("supabase_migrations.schema_migrations table conflicts with the contents of " + utils.Bold(".supabase/migrations") + ".")
+	conflictErr := errors.New("supabase_migrations.schema_migrations table conflicts with the contents of " + utils.Bold(".supabase/migrations") + ". Run " + utils.Aqua("supabase migration list") + " to compare them, and " + utils.Aqua("supabase migration repair") + " to fix the remote migration history")
 
 	if len(versions) > len(migrations) {
 		return fmt.Errorf("%w; Found %d versions and %d migrations.", conflictErr, len(versions), len(migrations))
 	}
 
 	if !dryRun {
+		if err := utils.RunScripts(ctx, nil, utils.BeforeMigrations, url); err != nil {
+			return err
+		}
+
 		fmt.Println("Applying unapplied migrations...")
 	}
 
-	re := regexp.MustCompile(`([0-9]+)_.*\.sql`)
-	for i, migration := range migrations {
-		matches := re.FindStringSubmatch(migration.Name())
+	for i, m := range migrations {
+		matches := utils.MigrationFilePattern.FindStringSubmatch(m.Name())
 		if len(matches) == 0 {
-			return errors.New("Can't process file in .supabase/migrations: " + migration.Name())
+			return errors.New("Can't process file in .supabase/migrations: " + m.Name())
 		}
 
 		migrationTimestamp := matches[1]
@@ -83,35 +74,24 @@ Try running `+utils.Aqua("supabase db remote set")+".", err)
 			return fmt.Errorf("%w; Expected version %s but found migration %s at index %d.", conflictErr, versions[i], migrationTimestamp, i)
 		}
 
-		f, err := os.ReadFile(".supabase/migrations/" + migration.Name())
+		f, err := os.ReadFile(".supabase/migrations/" + m.Name())
 		if err != nil {
 			return err
 		}
 
-		if err := func() error {
-			tx, err := conn.Begin(ctx)
-			if err != nil {
-				return fmt.Errorf("%w; while beginning migration %s", err, migrationTimestamp)
-			}
-			defer tx.Rollback(context.Background()) //nolint:errcheck
-
-			if dryRun {
-				fmt.Printf("Would apply migration %s:\n%s\n\n---\n\n", migration.Name(), f)
-			} else {
-				if _, err := tx.Exec(ctx, string(f)); err != nil {
-					return fmt.Errorf("%w; while executing migration %s", err, migrationTimestamp)
-				}
-				if _, err := tx.Exec(ctx, "INSERT INTO supabase_migrations.schema_migrations(version) VALUES('"+migrationTimestamp+"');"); err != nil {
-					return fmt.Errorf("%w; while inserting migration %s", err, migrationTimestamp)
-				}
-
-				if err := tx.Commit(ctx); err != nil {
-					return fmt.Errorf("%w; while committing migration %s", err, migrationTimestamp)
-				}
-			}
-
-			return nil
-		}(); err != nil {
+		if dryRun {
+			fmt.Printf("Would apply migration %s:\n%s\n\n---\n\n", m.Name(), f)
+			continue
+		}
+		// Each migration and its version are applied in a transaction, unless
+		// it has statements that can't run in one.
+		if err := migration.ExecMigration(ctx, conn, ".supabase/migrations/"+m.Name(), string(f), migrationTimestamp); err != nil {
+			return fmt.Errorf("%w; while executing migration %s", err, migrationTimestamp)
+		}
+	}
+
+	if !dryRun {
+		if err := utils.RunScripts(ctx, nil, utils.AfterMigrations, url); err != nil {
 			return err
 		}
 	}
@@ -49,73 +49,54 @@ func Run(url string) error {
 		)
 	}
 
-	// 2. Setup & validate `schema_migrations`.
+	// 2. Write .env. This happens before validating `schema_migrations` so
+	// that a diverged history can be fixed with `supabase migration repair`.
+	if err := utils.MkdirIfNotExist(".supabase/temp"); err != nil {
+		return err
+	}
+	if err := os.WriteFile(".supabase/temp/remote-db-url", []byte(url), 0600); err != nil {
+		return err
+	}
+
+	// 3. Setup & validate `schema_migrations`.
 
 	// If `schema_migrations` doesn't exist on the remote database, create it.
 	if _, err := conn.Exec(ctx, "SELECT 1 FROM supabase_migrations.schema_migrations"); err != nil {
-		if _, err := conn.Exec(
-			ctx,
-			`CREATE SCHEMA IF NOT EXISTS supabase_migrations;
-CREATE TABLE supabase_migrations.schema_migrations (version text NOT NULL PRIMARY KEY);
-`,
-		); err != nil {
+		if _, err := conn.Exec(ctx, utils.CreateMigrationTableSql); err != nil {
 			return err
 		}
 	}
 
 	// If `schema_migrations` is not a "prefix" of list of migrations in repo, fail &
 	// warn user.
-	rows, err := conn.Query(ctx, "SELECT version FROM supabase_migrations.schema_migrations ORDER BY version")
-	if err != nil {
-		return err
-	} else {
-		var remoteMigrations []string
-		for rows.Next() {
-			var version string
-			if err := rows.Scan(&version); err != nil {
-				return err
-			}
-			remoteMigrations = append(remoteMigrations, version)
-		}
-
-		if err := utils.MkdirIfNotExist(".supabase/migrations"); err != nil {
+	{
+		remoteMigrations, err := utils.GetMigrationHistory(ctx, conn)
+		if err != nil {
 			return err
 		}
-		localMigrations, err := os.ReadDir(".supabase/migrations")
+		localMigrations, err := utils.GetLocalMigrationVersions()
 		if err != nil {
 			return err
 		}
 
-		re := regexp.MustCompile(`([0-9]+)_.*\.sql`)
 		for i, remoteTimestamp := range remoteMigrations {
 			if i >= len(localMigrations) {
 				return errors.New(`The remote database was applied with migration(s) that cannot be found locally. Try updating the project from version control. Otherwise:
-1. Delete rows from supabase_migrations.schema_migrations on the remote database so that it's in sync with the contents of ` + utils.Bold(".supabase/migrations") + `,
-2. Run ` + utils.Aqua("supabase db remote set") + ` again,
-3. Run ` + utils.Aqua("supabase db remote commit") + ".")
+1. Run ` + utils.Aqua("supabase migration list") + ` to find the remote-only versions,
+2. Run ` + utils.Aqua("supabase migration repair --status reverted <version>...") + ` to remove them from the remote migration history,
+3. Run ` + utils.Aqua("supabase db remote set") + ` again,
+4. Run ` + utils.Aqua("supabase db remote commit") + ".")
 			}
 
-			localTimestamp := re.FindStringSubmatch(localMigrations[i].Name())[1]
-
-			if localTimestamp == remoteTimestamp {
+			if localMigrations[i] == remoteTimestamp {
 				continue
 			}
 
 			return errors.New(`The remote database was set up with a different Supabase CLI project. If you meant to reset the migration history to use a new Supabase CLI project:
-1. Run ` + utils.Aqua("DROP SCHEMA supabase_migrations CASCADE") + ` on the remote database,
-2. Run ` + utils.Aqua("supabase db remote set") + ` again,
-3. Run ` + utils.Aqua("supabase db remote commit") + ".")
+1. Run ` + utils.Aqua("supabase migration baseline <version>") + ` with the last local migration already reflected on the remote database,
+2. Run ` + utils.Aqua("supabase db remote set") + ` again.`)
 		}
 	}
-	rows.Close()
-
-	// 3. Write .env
-	if err := utils.MkdirIfNotExist(".supabase/temp"); err != nil {
-		return err
-	}
-	if err := os.WriteFile(".supabase/temp/remote-db-url", []byte(url), 0600); err != nil {
-		return err
-	}
 
 	fmt.Println("Finished " + utils.Aqua("supabase db remote set") + ".")
 	return nil
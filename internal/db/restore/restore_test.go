@@ -0,0 +1,87 @@
+package restore
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestFilterDump(t *testing.T) {
+	dump := `SET statement_timeout = 0;
+
+--
+-- Name: auth; Type: SCHEMA; Schema: -; Owner: supabase_admin
+--
+
+CREATE SCHEMA auth;
+
+--
+-- Name: SCHEMA auth; Type: COMMENT; Schema: -; Owner: supabase_admin
+--
+
+COMMENT ON SCHEMA auth IS 'Auth';
+
+--
+-- Name: users; Type: TABLE; Schema: auth; Owner: supabase_auth_admin
+--
+
+CREATE TABLE auth.users (id uuid);
+
+--
+-- Name: posts; Type: TABLE; Schema: public; Owner: postgres
+--
+
+CREATE TABLE public.posts (id bigint, body text);
+
+--
+-- Data for Name: users; Type: TABLE DATA; Schema: auth; Owner: supabase_auth_admin
+--
+
+COPY auth.users (id) FROM stdin;
+00000000-0000-0000-0000-000000000000
+\.
+
+--
+-- Data for Name: posts; Type: TABLE DATA; Schema: public; Owner: postgres
+--
+
+COPY public.posts (id, body) FROM stdin;
+1	-- Name: x; Type: TABLE; Schema: auth; Owner: y
+\.
+
+
+--
+-- PostgreSQL database dump complete
+--
+`
+
+	var out bytes.Buffer
+	if err := filterDump(strings.NewReader(dump), &out); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `SET statement_timeout = 0;
+
+--
+-- Name: posts; Type: TABLE; Schema: public; Owner: postgres
+--
+
+CREATE TABLE public.posts (id bigint, body text);
+
+--
+-- Data for Name: posts; Type: TABLE DATA; Schema: public; Owner: postgres
+--
+
+COPY public.posts (id, body) FROM stdin;
+1	-- Name: x; Type: TABLE; Schema: auth; Owner: y
+\.
+
+
+--
+-- PostgreSQL database dump complete
+--
+`
+	if out.String() != expected {
+		t.Errorf("unexpected output:\n%s", out.String())
+	}
+}
@@ -0,0 +1,736 @@
+package differ
+
+import (
+	"context"
+	"fmt"
+
+	pgx "github.com/jackc/pgx/v4"
+	"github.com/supabase/cli/internal/utils"
+)
+
+// Catalog is a snapshot of the user-defined objects of a database. Objects are
+// keyed by their qualified, quoted identity, e.g. `public."Users"`, so the
+// keys can be used as-is in generated DDL.
+type Catalog struct {
+	Extensions   map[string]*Extension
+	Schemas      map[string]*Schema
+	Enums        map[string]*Enum
+	Sequences    map[string]*Sequence
+	Functions    map[string]*Function
+	Tables       map[string]*Table
+	Constraints  map[string]*Constraint // Keyed by `<table> <name>`.
+	Indexes      map[string]*Index
+	Views        map[string]*View
+	Triggers     map[string]*Trigger // Keyed by `<table> <name>`.
+	Policies     map[string]*Policy  // Keyed by `<table> <name>`.
+	Acls         map[string]*Acl     // Keyed by `<object type> <name>`.
+	Publications map[string]*Publication
+}
+
+type Extension struct {
+	Name   string
+	Schema string
+}
+
+type Schema struct {
+	Name    string
+	Comment string
+}
+
+type Enum struct {
+	Name    string
+	Labels  []string
+	Comment string
+}
+
+type Sequence struct {
+	Name      string
+	DataType  string
+	Start     int64
+	Increment int64
+	Min       int64
+	Max       int64
+	Cache     int64
+	Cycle     bool
+	// Qualified column the sequence is owned by, e.g. for `serial` columns.
+	OwnedBy string
+	Comment string
+}
+
+type Function struct {
+	// Qualified name with argument types, e.g. `public.add(integer,integer)`.
+	Name string
+	// FUNCTION or PROCEDURE.
+	Kind       string
+	Result     string
+	Definition string
+	Comment    string
+}
+
+type Table struct {
+	Name           string
+	PartitionKey   string
+	PartitionOf    string
+	PartitionBound string
+	Columns        []*Column
+	// Whether row level security is enabled, and forced on the table owner.
+	RowSecurity      bool
+	ForceRowSecurity bool
+	Comment          string
+}
+
+type Column struct {
+	Name      string
+	Type      string
+	NotNull   bool
+	Default   string
+	Generated string
+	// `a` for GENERATED ALWAYS, `d` for GENERATED BY DEFAULT, or empty.
+	Identity  string
+	Collation string
+	Comment   string
+}
+
+type Constraint struct {
+	Table string
+	Name  string
+	// One of `p`, `u`, `c`, `f` or `x`, as in `pg_constraint.contype`.
+	Type       string
+	Definition string
+	Comment    string
+}
+
+type Index struct {
+	Name       string
+	Table      string
+	Definition string
+	Comment    string
+}
+
+type View struct {
+	Name         string
+	Materialized bool
+	Options      string
+	Definition   string
+	// Views and materialized views this view selects from.
+	Dependencies []string
+	Comment      string
+}
+
+type Trigger struct {
+	Table      string
+	Name       string
+	Definition string
+	Comment    string
+}
+
+type Policy struct {
+	Table      string
+	Name       string
+	Permissive bool
+	Command    string
+	Roles      []string
+	Using      string
+	WithCheck  string
+	Comment    string
+}
+
+// Acl holds the privileges granted on an object to roles other than its owner.
+type Acl struct {
+	// TABLE, SEQUENCE, FUNCTION, PROCEDURE or SCHEMA.
+	ObjectType string
+	Object     string
+	Privileges []Privilege
+	// Privileges an object of this type gets on creation.
+	Defaults []Privilege
+}
+
+type Privilege struct {
+	Grantee   string
+	Type      string
+	Grantable bool
+}
+
+type Publication struct {
+	Name      string
+	AllTables bool
+	Publish   string
+	Tables    []string
+}
+
+// Excludes internal schemas. Expects the schema to be aliased as `n` and the
+// internal schemas to be passed as $1.
+const schemaFilter = `n.nspname::text <> ALL($1::text[]) AND n.nspname !~ '^pg_' AND n.nspname <> 'information_schema'`
+
+// Excludes objects created by extensions, which are diffed as part of the
+// extension itself.
+func notExtensionMember(catalog string, oid string) string {
+	return fmt.Sprintf(
+		`NOT EXISTS (SELECT 1 FROM pg_depend d WHERE d.classid = '%s'::regclass AND d.objid = %s AND d.deptype = 'e')`,
+		catalog,
+		oid,
+	)
+}
+
+// LoadCatalog introspects the database behind conn. Objects in
+// utils.InternalSchemas and objects owned by extensions are left out.
+func LoadCatalog(ctx context.Context, conn *pgx.Conn) (*Catalog, error) {
+	tx, err := conn.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(context.Background()) //nolint:errcheck
+
+	// Make definitions (defaults, view bodies, etc.) fully qualified, so
+	// they don't depend on the search_path of whoever applies the diff.
+	if _, err := tx.Exec(ctx, "SET LOCAL search_path = ''"); err != nil {
+		return nil, err
+	}
+
+	c := &Catalog{
+		Extensions:   map[string]*Extension{},
+		Schemas:      map[string]*Schema{},
+		Enums:        map[string]*Enum{},
+		Sequences:    map[string]*Sequence{},
+		Functions:    map[string]*Function{},
+		Tables:       map[string]*Table{},
+		Constraints:  map[string]*Constraint{},
+		Indexes:      map[string]*Index{},
+		Views:        map[string]*View{},
+		Triggers:     map[string]*Trigger{},
+		Policies:     map[string]*Policy{},
+		Acls:         map[string]*Acl{},
+		Publications: map[string]*Publication{},
+	}
+
+	for _, load := range []func(context.Context, pgx.Tx, *Catalog) error{
+		loadExtensions,
+		loadSchemas,
+		loadEnums,
+		loadSequences,
+		loadFunctions,
+		loadTables,
+		loadColumns,
+		loadConstraints,
+		loadIndexes,
+		loadViews,
+		loadTriggers,
+		loadPolicies,
+		loadAcls,
+		loadPublications,
+	} {
+		if err := load(ctx, tx, c); err != nil {
+			return nil, fmt.Errorf("Error introspecting database: %w", err)
+		}
+	}
+
+	return c, nil
+}
+
+func loadExtensions(ctx context.Context, tx pgx.Tx, c *Catalog) error {
+	rows, err := tx.Query(ctx, `
+SELECT quote_ident(e.extname), quote_ident(n.nspname)
+FROM pg_extension e
+JOIN pg_namespace n ON n.oid = e.extnamespace`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var e Extension
+		if err := rows.Scan(&e.Name, &e.Schema); err != nil {
+			return err
+		}
+		c.Extensions[e.Name] = &e
+	}
+
+	return rows.Err()
+}
+
+func loadSchemas(ctx context.Context, tx pgx.Tx, c *Catalog) error {
+	rows, err := tx.Query(ctx, `
+SELECT quote_ident(n.nspname), coalesce(obj_description(n.oid, 'pg_namespace'), '')
+FROM pg_namespace n
+WHERE `+schemaFilter+` AND `+notExtensionMember("pg_namespace", "n.oid"), utils.InternalSchemas)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var s Schema
+		if err := rows.Scan(&s.Name, &s.Comment); err != nil {
+			return err
+		}
+		c.Schemas[s.Name] = &s
+	}
+
+	return rows.Err()
+}
+
+func loadEnums(ctx context.Context, tx pgx.Tx, c *Catalog) error {
+	rows, err := tx.Query(ctx, `
+SELECT
+  format('%I.%I', n.nspname, t.typname),
+  ARRAY(SELECT e.enumlabel::text FROM pg_enum e WHERE e.enumtypid = t.oid ORDER BY e.enumsortorder),
+  coalesce(obj_description(t.oid, 'pg_type'), '')
+FROM pg_type t
+JOIN pg_namespace n ON n.oid = t.typnamespace
+WHERE t.typtype = 'e' AND `+schemaFilter+` AND `+notExtensionMember("pg_type", "t.oid"), utils.InternalSchemas)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var e Enum
+		if err := rows.Scan(&e.Name, &e.Labels, &e.Comment); err != nil {
+			return err
+		}
+		c.Enums[e.Name] = &e
+	}
+
+	return rows.Err()
+}
+
+func loadSequences(ctx context.Context, tx pgx.Tx, c *Catalog) error {
+	// Identity sequences are left out since they're managed through their
+	// columns.
+	rows, err := tx.Query(ctx, `
+SELECT
+  format('%I.%I', n.nspname, c.relname),
+  format_type(s.seqtypid, NULL),
+  s.seqstart,
+  s.seqincrement,
+  s.seqmin,
+  s.seqmax,
+  s.seqcache,
+  s.seqcycle,
+  coalesce((
+    SELECT format('%I.%I.%I', tn.nspname, tc.relname, a.attname)
+    FROM pg_depend d
+    JOIN pg_class tc ON tc.oid = d.refobjid
+    JOIN pg_namespace tn ON tn.oid = tc.relnamespace
+    JOIN pg_attribute a ON a.attrelid = d.refobjid AND a.attnum = d.refobjsubid
+    WHERE d.classid = 'pg_class'::regclass AND d.objid = c.oid AND d.refclassid = 'pg_class'::regclass AND d.deptype = 'a'
+  ), ''),
+  coalesce(obj_description(c.oid, 'pg_class'), '')
+FROM pg_sequence s
+JOIN pg_class c ON c.oid = s.seqrelid
+JOIN pg_namespace n ON n.oid = c.relnamespace
+WHERE `+schemaFilter+` AND `+notExtensionMember("pg_class", "c.oid")+`
+  AND NOT EXISTS (SELECT 1 FROM pg_depend d WHERE d.classid = 'pg_class'::regclass AND d.objid = c.oid AND d.deptype = 'i')`, utils.InternalSchemas)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var s Sequence
+		if err := rows.Scan(&s.Name, &s.DataType, &s.Start, &s.Increment, &s.Min, &s.Max, &s.Cache, &s.Cycle, &s.OwnedBy, &s.Comment); err != nil {
+			return err
+		}
+		c.Sequences[s.Name] = &s
+	}
+
+	return rows.Err()
+}
+
+func loadFunctions(ctx context.Context, tx pgx.Tx, c *Catalog) error {
+	rows, err := tx.Query(ctx, `
+SELECT
+  p.oid::regprocedure::text,
+  CASE p.prokind WHEN 'p' THEN 'PROCEDURE' ELSE 'FUNCTION' END,
+  coalesce(pg_get_function_result(p.oid), ''),
+  pg_get_functiondef(p.oid),
+  coalesce(obj_description(p.oid, 'pg_proc'), '')
+FROM pg_proc p
+JOIN pg_namespace n ON n.oid = p.pronamespace
+WHERE p.prokind IN ('f', 'p') AND `+schemaFilter+` AND `+notExtensionMember("pg_proc", "p.oid"), utils.InternalSchemas)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var f Function
+		if err := rows.Scan(&f.Name, &f.Kind, &f.Result, &f.Definition, &f.Comment); err != nil {
+			return err
+		}
+		c.Functions[f.Name] = &f
+	}
+
+	return rows.Err()
+}
+
+// Tables (incl. partitioned tables), excluding internal ones. Expects the table
+// to be aliased as `c`.
+const tableFilter = `c.relkind IN ('r', 'p') AND ` + schemaFilter
+
+func loadTables(ctx context.Context, tx pgx.Tx, c *Catalog) error {
+	rows, err := tx.Query(ctx, `
+SELECT
+  format('%I.%I', n.nspname, c.relname),
+  coalesce(pg_get_partkeydef(c.oid), ''),
+  coalesce((
+    SELECT format('%I.%I', pn.nspname, pc.relname)
+    FROM pg_inherits i
+    JOIN pg_class pc ON pc.oid = i.inhparent
+    JOIN pg_namespace pn ON pn.oid = pc.relnamespace
+    WHERE c.relispartition AND i.inhrelid = c.oid
+  ), ''),
+  CASE WHEN c.relispartition THEN pg_get_expr(c.relpartbound, c.oid) ELSE '' END,
+  c.relrowsecurity,
+  c.relforcerowsecurity,
+  coalesce(obj_description(c.oid, 'pg_class'), '')
+FROM pg_class c
+JOIN pg_namespace n ON n.oid = c.relnamespace
+WHERE `+tableFilter+` AND `+notExtensionMember("pg_class", "c.oid"), utils.InternalSchemas)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var t Table
+		if err := rows.Scan(&t.Name, &t.PartitionKey, &t.PartitionOf, &t.PartitionBound, &t.RowSecurity, &t.ForceRowSecurity, &t.Comment); err != nil {
+			return err
+		}
+		c.Tables[t.Name] = &t
+	}
+
+	return rows.Err()
+}
+
+func loadColumns(ctx context.Context, tx pgx.Tx, c *Catalog) error {
+	// Inherited columns (e.g. of partitions) are left out since they're
+	// managed through the parent.
+	rows, err := tx.Query(ctx, `
+SELECT
+  format('%I.%I', n.nspname, c.relname),
+  quote_ident(a.attname),
+  format_type(a.atttypid, a.atttypmod),
+  a.attnotnull,
+  CASE WHEN a.attgenerated = '' THEN coalesce(pg_get_expr(ad.adbin, ad.adrelid), '') ELSE '' END,
+  CASE WHEN a.attgenerated <> '' THEN coalesce(pg_get_expr(ad.adbin, ad.adrelid), '') ELSE '' END,
+  a.attidentity::text,
+  coalesce((
+    SELECT format('%I.%I', cn.nspname, co.collname)
+    FROM pg_collation co
+    JOIN pg_namespace cn ON cn.oid = co.collnamespace
+    WHERE co.oid = a.attcollation AND a.attcollation <> t.typcollation
+  ), ''),
+  coalesce(col_description(c.oid, a.attnum), '')
+FROM pg_attribute a
+JOIN pg_class c ON c.oid = a.attrelid
+JOIN pg_namespace n ON n.oid = c.relnamespace
+JOIN pg_type t ON t.oid = a.atttypid
+LEFT JOIN pg_attrdef ad ON ad.adrelid = a.attrelid AND ad.adnum = a.attnum
+WHERE a.attnum > 0 AND NOT a.attisdropped AND a.attislocal AND `+tableFilter+` AND `+notExtensionMember("pg_class", "c.oid")+`
+ORDER BY c.oid, a.attnum`, utils.InternalSchemas)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var table string
+		var col Column
+		if err := rows.Scan(&table, &col.Name, &col.Type, &col.NotNull, &col.Default, &col.Generated, &col.Identity, &col.Collation, &col.Comment); err != nil {
+			return err
+		}
+		if t, ok := c.Tables[table]; ok {
+			t.Columns = append(t.Columns, &col)
+		}
+	}
+
+	return rows.Err()
+}
+
+func loadConstraints(ctx context.Context, tx pgx.Tx, c *Catalog) error {
+	// NOT NULL constraints are handled as part of columns.
+	rows, err := tx.Query(ctx, `
+SELECT
+  format('%I.%I', n.nspname, c.relname),
+  quote_ident(con.conname),
+  con.contype::text,
+  pg_get_constraintdef(con.oid),
+  coalesce(obj_description(con.oid, 'pg_constraint'), '')
+FROM pg_constraint con
+JOIN pg_class c ON c.oid = con.conrelid
+JOIN pg_namespace n ON n.oid = c.relnamespace
+WHERE con.contype IN ('p', 'u', 'c', 'f', 'x') AND con.conislocal AND `+tableFilter+` AND `+notExtensionMember("pg_class", "c.oid"), utils.InternalSchemas)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var con Constraint
+		if err := rows.Scan(&con.Table, &con.Name, &con.Type, &con.Definition, &con.Comment); err != nil {
+			return err
+		}
+		c.Constraints[con.Table+" "+con.Name] = &con
+	}
+
+	return rows.Err()
+}
+
+func loadIndexes(ctx context.Context, tx pgx.Tx, c *Catalog) error {
+	// Indexes backing constraints are created with the constraints, and
+	// indexes of partitions with the index of the parent.
+	rows, err := tx.Query(ctx, `
+SELECT
+  format('%I.%I', n.nspname, ic.relname),
+  format('%I.%I', n.nspname, c.relname),
+  pg_get_indexdef(i.indexrelid),
+  coalesce(obj_description(ic.oid, 'pg_class'), '')
+FROM pg_index i
+JOIN pg_class ic ON ic.oid = i.indexrelid
+JOIN pg_class c ON c.oid = i.indrelid
+JOIN pg_namespace n ON n.oid = c.relnamespace
+WHERE c.relkind IN ('r', 'p', 'm') AND `+schemaFilter+` AND `+notExtensionMember("pg_class", "c.oid")+`
+  AND NOT EXISTS (
+    SELECT 1 FROM pg_constraint con
+    WHERE con.conrelid = i.indrelid AND con.conindid = i.indexrelid AND con.contype IN ('p', 'u', 'x')
+  )
+  AND NOT EXISTS (SELECT 1 FROM pg_inherits inh WHERE inh.inhrelid = i.indexrelid)`, utils.InternalSchemas)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var idx Index
+		if err := rows.Scan(&idx.Name, &idx.Table, &idx.Definition, &idx.Comment); err != nil {
+			return err
+		}
+		c.Indexes[idx.Name] = &idx
+	}
+
+	return rows.Err()
+}
+
+func loadViews(ctx context.Context, tx pgx.Tx, c *Catalog) error {
+	rows, err := tx.Query(ctx, `
+SELECT
+  format('%I.%I', n.nspname, c.relname),
+  c.relkind = 'm',
+  coalesce(array_to_string(c.reloptions, ', '), ''),
+  pg_get_viewdef(c.oid, true),
+  ARRAY(
+    SELECT DISTINCT format('%I.%I', dn.nspname, dc.relname)
+    FROM pg_rewrite r
+    JOIN pg_depend d ON d.classid = 'pg_rewrite'::regclass AND d.objid = r.oid AND d.refclassid = 'pg_class'::regclass
+    JOIN pg_class dc ON dc.oid = d.refobjid
+    JOIN pg_namespace dn ON dn.oid = dc.relnamespace
+    WHERE r.ev_class = c.oid AND dc.oid <> c.oid AND dc.relkind IN ('v', 'm')
+  ),
+  coalesce(obj_description(c.oid, 'pg_class'), '')
+FROM pg_class c
+JOIN pg_namespace n ON n.oid = c.relnamespace
+WHERE c.relkind IN ('v', 'm') AND `+schemaFilter+` AND `+notExtensionMember("pg_class", "c.oid"), utils.InternalSchemas)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var v View
+		if err := rows.Scan(&v.Name, &v.Materialized, &v.Options, &v.Definition, &v.Dependencies, &v.Comment); err != nil {
+			return err
+		}
+		c.Views[v.Name] = &v
+	}
+
+	return rows.Err()
+}
+
+func loadTriggers(ctx context.Context, tx pgx.Tx, c *Catalog) error {
+	// Triggers cloned onto partitions are managed through the parent.
+	rows, err := tx.Query(ctx, `
+SELECT
+  format('%I.%I', n.nspname, c.relname),
+  quote_ident(t.tgname),
+  pg_get_triggerdef(t.oid),
+  coalesce(obj_description(t.oid, 'pg_trigger'), '')
+FROM pg_trigger t
+JOIN pg_class c ON c.oid = t.tgrelid
+JOIN pg_namespace n ON n.oid = c.relnamespace
+WHERE NOT t.tgisinternal AND t.tgparentid = 0 AND `+schemaFilter+` AND `+notExtensionMember("pg_class", "c.oid"), utils.InternalSchemas)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var t Trigger
+		if err := rows.Scan(&t.Table, &t.Name, &t.Definition, &t.Comment); err != nil {
+			return err
+		}
+		c.Triggers[t.Table+" "+t.Name] = &t
+	}
+
+	return rows.Err()
+}
+
+func loadPolicies(ctx context.Context, tx pgx.Tx, c *Catalog) error {
+	rows, err := tx.Query(ctx, `
+SELECT
+  format('%I.%I', n.nspname, c.relname),
+  quote_ident(pol.polname),
+  pol.polpermissive,
+  CASE pol.polcmd WHEN 'r' THEN 'SELECT' WHEN 'a' THEN 'INSERT' WHEN 'w' THEN 'UPDATE' WHEN 'd' THEN 'DELETE' ELSE 'ALL' END,
+  ARRAY(
+    SELECT CASE WHEN r = 0 THEN 'PUBLIC' ELSE quote_ident(pg_get_userbyid(r)) END
+    FROM unnest(pol.polroles) r
+    ORDER BY 1
+  ),
+  coalesce(pg_get_expr(pol.polqual, pol.polrelid), ''),
+  coalesce(pg_get_expr(pol.polwithcheck, pol.polrelid), ''),
+  coalesce(obj_description(pol.oid, 'pg_policy'), '')
+FROM pg_policy pol
+JOIN pg_class c ON c.oid = pol.polrelid
+JOIN pg_namespace n ON n.oid = c.relnamespace
+WHERE `+schemaFilter+` AND `+notExtensionMember("pg_class", "c.oid"), utils.InternalSchemas)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var p Policy
+		if err := rows.Scan(&p.Table, &p.Name, &p.Permissive, &p.Command, &p.Roles, &p.Using, &p.WithCheck, &p.Comment); err != nil {
+			return err
+		}
+		c.Policies[p.Table+" "+p.Name] = &p
+	}
+
+	return rows.Err()
+}
+
+func loadAcls(ctx context.Context, tx pgx.Tx, c *Catalog) error {
+	// A NULL ACL means the object has the built-in default privileges for its
+	// type. Newly created objects additionally get the privileges configured
+	// with ALTER DEFAULT PRIVILEGES for their owner, in their schema.
+	rows, err := tx.Query(ctx, `
+WITH objects AS (
+  SELECT
+    CASE c.relkind WHEN 'S' THEN 'SEQUENCE' ELSE 'TABLE' END AS type,
+    format('%I.%I', n.nspname, c.relname) AS name,
+    c.relowner AS owner,
+    n.oid AS namespace,
+    c.relacl AS acl,
+    CASE c.relkind WHEN 'S' THEN 's' ELSE 'r' END::"char" AS acltype,
+    CASE c.relkind WHEN 'S' THEN 'S' ELSE 'r' END::"char" AS defacltype
+  FROM pg_class c
+  JOIN pg_namespace n ON n.oid = c.relnamespace
+  WHERE c.relkind IN ('r', 'p', 'v', 'm', 'S') AND NOT c.relispartition AND `+schemaFilter+` AND `+notExtensionMember("pg_class", "c.oid")+`
+    AND NOT EXISTS (SELECT 1 FROM pg_depend d WHERE d.classid = 'pg_class'::regclass AND d.objid = c.oid AND d.deptype = 'i')
+  UNION ALL
+  SELECT
+    CASE p.prokind WHEN 'p' THEN 'PROCEDURE' ELSE 'FUNCTION' END,
+    p.oid::regprocedure::text,
+    p.proowner,
+    n.oid,
+    p.proacl,
+    'f',
+    'f'
+  FROM pg_proc p
+  JOIN pg_namespace n ON n.oid = p.pronamespace
+  WHERE p.prokind IN ('f', 'p') AND `+schemaFilter+` AND `+notExtensionMember("pg_proc", "p.oid")+`
+  UNION ALL
+  SELECT 'SCHEMA', quote_ident(n.nspname), n.nspowner, 0::oid, n.nspacl, 'n', 'n'
+  FROM pg_namespace n
+  WHERE `+schemaFilter+` AND `+notExtensionMember("pg_namespace", "n.oid")+`
+),
+objects_with_defaults AS (
+  SELECT
+    o.*,
+    coalesce(
+      (SELECT da.defaclacl FROM pg_default_acl da WHERE da.defaclrole = o.owner AND da.defaclnamespace = 0 AND da.defaclobjtype = o.defacltype),
+      acldefault(o.acltype, o.owner)
+    ) || coalesce(
+      (SELECT da.defaclacl FROM pg_default_acl da WHERE da.defaclrole = o.owner AND da.defaclnamespace = o.namespace AND o.namespace <> 0 AND da.defaclobjtype = o.defacltype),
+      '{}'
+    ) AS defaults
+  FROM objects o
+)
+SELECT DISTINCT
+  o.type,
+  o.name,
+  a.is_default,
+  CASE WHEN a.grantee = 0 THEN 'PUBLIC' ELSE quote_ident(pg_get_userbyid(a.grantee)) END,
+  a.privilege_type,
+  a.is_grantable
+FROM objects_with_defaults o
+CROSS JOIN LATERAL (
+  SELECT false AS is_default, x.* FROM aclexplode(coalesce(o.acl, acldefault(o.acltype, o.owner))) x
+  UNION ALL
+  SELECT true, x.* FROM aclexplode(o.defaults) x
+) a
+WHERE a.grantee <> o.owner
+ORDER BY 1, 2, 4, 5`, utils.InternalSchemas)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var objectType, object string
+		var isDefault bool
+		var p Privilege
+		if err := rows.Scan(&objectType, &object, &isDefault, &p.Grantee, &p.Type, &p.Grantable); err != nil {
+			return err
+		}
+
+		key := objectType + " " + object
+		acl, ok := c.Acls[key]
+		if !ok {
+			acl = &Acl{ObjectType: objectType, Object: object}
+			c.Acls[key] = acl
+		}
+		if isDefault {
+			acl.Defaults = append(acl.Defaults, p)
+		} else {
+			acl.Privileges = append(acl.Privileges, p)
+		}
+	}
+
+	return rows.Err()
+}
+
+func loadPublications(ctx context.Context, tx pgx.Tx, c *Catalog) error {
+	rows, err := tx.Query(ctx, `
+SELECT
+  quote_ident(p.pubname),
+  p.puballtables,
+  array_to_string(ARRAY[
+    CASE WHEN p.pubinsert THEN 'insert' END,
+    CASE WHEN p.pubupdate THEN 'update' END,
+    CASE WHEN p.pubdelete THEN 'delete' END,
+    CASE WHEN p.pubtruncate THEN 'truncate' END
+  ], ', '),
+  ARRAY(
+    SELECT format('%I.%I', pt.schemaname, pt.tablename)
+    FROM pg_publication_tables pt
+    WHERE NOT p.puballtables AND pt.pubname = p.pubname AND pt.schemaname::text <> ALL($1::text[])
+    ORDER BY 1
+  )
+FROM pg_publication p`, utils.InternalSchemas)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var p Publication
+		if err := rows.Scan(&p.Name, &p.AllTables, &p.Publish, &p.Tables); err != nil {
+			return err
+		}
+		c.Publications[p.Name] = &p
+	}
+
+	return rows.Err()
+}
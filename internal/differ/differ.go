@@ -0,0 +1,940 @@
+package differ
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	pgx "github.com/jackc/pgx/v4"
+)
+
+// Class is the class of object a Statement applies to. Classes are declared in
+// dependency order: objects of a class may only depend on objects of the same
+// or earlier classes.
+type Class int
+
+const (
+	ClassExtension Class = iota
+	ClassSchema
+	ClassEnum
+	ClassSequence
+	ClassFunction
+	ClassTable
+	ClassConstraint
+	ClassIndex
+	ClassView
+	// Functions whose signature or body refers to a table or view created or
+	// dropped by the migration, e.g. `RETURNS SETOF new_table`.
+	ClassRelationFunction
+	ClassTrigger
+	ClassPolicy
+	ClassGrant
+	ClassPublication
+	ClassComment
+
+	numClasses
+)
+
+var classNames = [numClasses]string{
+	"Extensions",
+	"Schemas",
+	"Enums",
+	"Sequences",
+	"Functions",
+	"Tables",
+	"Constraints",
+	"Indexes",
+	"Views",
+	"Functions depending on tables and views",
+	"Triggers",
+	"Row level security policies",
+	"Grants",
+	"Publications",
+	"Comments",
+}
+
+func (c Class) String() string {
+	return classNames[c]
+}
+
+type Statement struct {
+	Class Class
+	// Whether the statement drops (part of) an object.
+	Drop bool
+	Sql  string
+}
+
+// DiffDatabases returns a migration that transforms the database at targetUrl
+// into the database at sourceUrl.
+func DiffDatabases(ctx context.Context, sourceUrl string, targetUrl string) ([]byte, error) {
+	source, err := loadCatalogFromUrl(ctx, sourceUrl)
+	if err != nil {
+		return nil, err
+	}
+	target, err := loadCatalogFromUrl(ctx, targetUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	return Format(Diff(source, target)), nil
+}
+
+func loadCatalogFromUrl(ctx context.Context, url string) (*Catalog, error) {
+	conn, err := pgx.Connect(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close(context.Background())
+
+	return LoadCatalog(ctx, conn)
+}
+
+// Format renders statements as a migration script.
+func Format(stmts []Statement) []byte {
+	lines := []string{`-- This script was generated by the Schema Diff utility of the Supabase CLI.
+-- Dependencies the catalog doesn't record (e.g. between function bodies) are not
+-- taken into account, so the script may require manual reordering.`}
+
+	for _, stmt := range stmts {
+		if stmt.Class == ClassFunction || stmt.Class == ClassRelationFunction {
+			// Like pg_dump, so function bodies can refer to objects created later.
+			lines = append(lines, "SET check_function_bodies = false;")
+			break
+		}
+	}
+
+	// Each run of statements of the same class gets a section header.
+	for i, stmt := range stmts {
+		if i > 0 && stmts[i-1].Class == stmt.Class && stmts[i-1].Drop == stmt.Drop {
+			lines = append(lines, stmt.Sql)
+			continue
+		}
+
+		header := "-- " + stmt.Class.String()
+		if stmt.Drop {
+			header = "-- Drop " + strings.ToLower(stmt.Class.String())
+		}
+		lines = append(lines, header+"\n"+stmt.Sql)
+	}
+
+	return []byte(strings.Join(lines, "\n\n") + "\n")
+}
+
+// Diff returns the statements that transform target into source. Objects are
+// dropped in reverse dependency order before any object is created or altered
+// in dependency order.
+func Diff(source *Catalog, target *Catalog) []Statement {
+	d := differ{source: source, target: target, recreated: map[string]bool{}}
+
+	d.diffExtensions()
+	d.diffSchemas()
+	d.diffEnums()
+	d.diffSequences()
+	d.diffFunctions()
+	d.diffTables()
+	d.diffConstraints()
+	d.diffIndexes()
+	d.diffViews()
+	d.diffTriggers()
+	d.diffPolicies()
+	d.diffAcls()
+	d.diffPublications()
+
+	stmts := []Statement{}
+	for class := numClasses - 1; class >= 0; class-- {
+		stmts = append(stmts, d.drops[class]...)
+	}
+	for class := Class(0); class < numClasses; class++ {
+		stmts = append(stmts, d.creates[class]...)
+	}
+	return stmts
+}
+
+type differ struct {
+	source *Catalog
+	target *Catalog
+
+	drops   [numClasses][]Statement
+	creates [numClasses][]Statement
+
+	// ACL keys of objects that are dropped and created again, thereby
+	// losing their privileges.
+	recreated map[string]bool
+}
+
+func (d *differ) drop(class Class, format string, args ...interface{}) {
+	d.drops[class] = append(d.drops[class], Statement{Class: class, Drop: true, Sql: fmt.Sprintf(format, args...)})
+}
+
+func (d *differ) create(class Class, format string, args ...interface{}) {
+	d.creates[class] = append(d.creates[class], Statement{Class: class, Sql: fmt.Sprintf(format, args...)})
+}
+
+// comment sets the comment on an object if it changed. If the object is new or
+// recreated, target should be empty.
+func (d *differ) comment(object string, source string, target string) {
+	if source == target {
+		return
+	}
+	if source == "" {
+		d.create(ClassComment, "COMMENT ON %s IS NULL;", object)
+	} else {
+		d.create(ClassComment, "COMMENT ON %s IS %s;", object, quoteLiteral(source))
+	}
+}
+
+func (d *differ) diffExtensions() {
+	for _, name := range sortedKeys(d.target.Extensions) {
+		if _, ok := d.source.Extensions[name]; !ok {
+			d.drop(ClassExtension, "DROP EXTENSION IF EXISTS %s;", name)
+		}
+	}
+
+	for _, name := range sortedKeys(d.source.Extensions) {
+		s := d.source.Extensions[name]
+		t, ok := d.target.Extensions[name]
+		if !ok {
+			d.create(ClassExtension, "CREATE EXTENSION IF NOT EXISTS %s WITH SCHEMA %s;", name, s.Schema)
+		} else if s.Schema != t.Schema {
+			d.create(ClassExtension, "ALTER EXTENSION %s SET SCHEMA %s;", name, s.Schema)
+		}
+	}
+}
+
+func (d *differ) diffSchemas() {
+	for _, name := range sortedKeys(d.target.Schemas) {
+		if _, ok := d.source.Schemas[name]; !ok {
+			d.drop(ClassSchema, "DROP SCHEMA IF EXISTS %s;", name)
+		}
+	}
+
+	for _, name := range sortedKeys(d.source.Schemas) {
+		s := d.source.Schemas[name]
+		var targetComment string
+		if t, ok := d.target.Schemas[name]; ok {
+			targetComment = t.Comment
+		} else {
+			d.create(ClassSchema, "CREATE SCHEMA IF NOT EXISTS %s;", name)
+		}
+		d.comment("SCHEMA "+name, s.Comment, targetComment)
+	}
+}
+
+func (d *differ) diffEnums() {
+	for _, name := range sortedKeys(d.target.Enums) {
+		if _, ok := d.source.Enums[name]; !ok {
+			d.drop(ClassEnum, "DROP TYPE IF EXISTS %s;", name)
+		}
+	}
+
+	for _, name := range sortedKeys(d.source.Enums) {
+		s := d.source.Enums[name]
+		var targetComment string
+		if t, ok := d.target.Enums[name]; !ok {
+			labels := make([]string, len(s.Labels))
+			for i, label := range s.Labels {
+				labels[i] = quoteLiteral(label)
+			}
+			d.create(ClassEnum, "CREATE TYPE %s AS ENUM (%s);", name, strings.Join(labels, ", "))
+		} else {
+			targetComment = t.Comment
+			d.alterEnum(name, s.Labels, t.Labels)
+		}
+		d.comment("TYPE "+name, s.Comment, targetComment)
+	}
+}
+
+// Enum values can be added in place, but not removed or reordered.
+func (d *differ) alterEnum(name string, source []string, target []string) {
+	i := 0
+	added := []string{}
+	for j, label := range source {
+		if i < len(target) && target[i] == label {
+			i++
+			continue
+		}
+		if j > 0 {
+			added = append(added, fmt.Sprintf("ALTER TYPE %s ADD VALUE %s AFTER %s;", name, quoteLiteral(label), quoteLiteral(source[j-1])))
+		} else if len(target) > 0 {
+			added = append(added, fmt.Sprintf("ALTER TYPE %s ADD VALUE %s BEFORE %s;", name, quoteLiteral(label), quoteLiteral(target[0])))
+		} else {
+			added = append(added, fmt.Sprintf("ALTER TYPE %s ADD VALUE %s;", name, quoteLiteral(label)))
+		}
+	}
+
+	if i < len(target) {
+		d.create(ClassEnum, "-- WARNING: Values of enum %s were removed or reordered, which can't be done in place. Recreate the type manually.", name)
+		return
+	}
+	for _, stmt := range added {
+		d.create(ClassEnum, "%s", stmt)
+	}
+}
+
+func (d *differ) diffSequences() {
+	for _, name := range sortedKeys(d.target.Sequences) {
+		if _, ok := d.source.Sequences[name]; !ok {
+			d.drop(ClassSequence, "DROP SEQUENCE IF EXISTS %s;", name)
+		}
+	}
+
+	for _, name := range sortedKeys(d.source.Sequences) {
+		s := d.source.Sequences[name]
+		var targetComment, targetOwnedBy string
+		if t, ok := d.target.Sequences[name]; !ok {
+			d.create(ClassSequence, "CREATE SEQUENCE IF NOT EXISTS %s %s;", name, sequenceOptions(s))
+		} else {
+			targetComment = t.Comment
+			targetOwnedBy = t.OwnedBy
+			if sequenceOptions(s) != sequenceOptions(t) {
+				d.create(ClassSequence, "ALTER SEQUENCE %s %s;", name, sequenceOptions(s))
+			}
+		}
+
+		// Owning columns only exist once tables and their columns are created,
+		// which is after all statements of ClassTable.
+		if s.OwnedBy != targetOwnedBy {
+			ownedBy := s.OwnedBy
+			if ownedBy == "" {
+				ownedBy = "NONE"
+			}
+			d.create(ClassConstraint, "ALTER SEQUENCE %s OWNED BY %s;", name, ownedBy)
+		}
+		d.comment("SEQUENCE "+name, s.Comment, targetComment)
+	}
+}
+
+func sequenceOptions(s *Sequence) string {
+	cycle := "NO CYCLE"
+	if s.Cycle {
+		cycle = "CYCLE"
+	}
+	return fmt.Sprintf(
+		"AS %s START WITH %d INCREMENT BY %d MINVALUE %d MAXVALUE %d CACHE %d %s",
+		s.DataType, s.Start, s.Increment, s.Min, s.Max, s.Cache, cycle,
+	)
+}
+
+func (d *differ) diffFunctions() {
+	dropped := newRelations(d.target, d.source)
+	created := newRelations(d.source, d.target)
+
+	for _, name := range sortedKeys(d.target.Functions) {
+		t := d.target.Functions[name]
+		s, ok := d.source.Functions[name]
+		// CREATE OR REPLACE can't change the result type.
+		if !ok || s.Kind != t.Kind || s.Result != t.Result {
+			d.drop(functionClass(t, dropped), "DROP %s IF EXISTS %s;", t.Kind, name)
+		}
+	}
+
+	for _, name := range sortedKeys(d.source.Functions) {
+		s := d.source.Functions[name]
+		var targetComment string
+		if t, ok := d.target.Functions[name]; !ok || s.Kind != t.Kind || s.Result != t.Result {
+			if ok {
+				d.recreated[s.Kind+" "+name] = true
+			}
+			d.create(functionClass(s, created), "%s", terminate(s.Definition))
+		} else {
+			targetComment = t.Comment
+			if s.Definition != t.Definition {
+				d.create(functionClass(s, created), "%s", terminate(s.Definition))
+			}
+		}
+		d.comment(s.Kind+" "+name, s.Comment, targetComment)
+	}
+}
+
+// newRelations returns a pattern matching references to the tables and views
+// of c that other lacks, i.e. ones created or dropped alongside functions, or
+// nil if there are none. References are matched by name, so a column of the
+// same name only makes a function run later than it needs to.
+func newRelations(c *Catalog, other *Catalog) *regexp.Regexp {
+	names := []string{}
+	add := func(name string) {
+		names = append(names, regexp.QuoteMeta(name))
+		// Relations on the search path may be referred to unqualified.
+		if i := strings.LastIndex(name, "."); i >= 0 {
+			names = append(names, regexp.QuoteMeta(name[i+1:]))
+		}
+	}
+	for _, name := range sortedKeys(c.Tables) {
+		if _, ok := other.Tables[name]; !ok {
+			add(name)
+		}
+	}
+	for _, name := range sortedKeys(c.Views) {
+		if _, ok := other.Views[name]; !ok {
+			add(name)
+		}
+	}
+	if len(names) == 0 {
+		return nil
+	}
+	return regexp.MustCompile(`(^|[^\w."])(?:` + strings.Join(names, "|") + `)($|[^\w"])`)
+}
+
+// functionClass returns ClassRelationFunction if the function refers to a
+// relation matched by relations, as returned by newRelations.
+func functionClass(f *Function, relations *regexp.Regexp) Class {
+	if relations != nil && relations.MatchString(f.Definition) {
+		return ClassRelationFunction
+	}
+	return ClassFunction
+}
+
+func (d *differ) diffTables() {
+	for _, name := range sortedTables(d.target.Tables, true) {
+		if _, ok := d.source.Tables[name]; !ok {
+			d.drop(ClassTable, "DROP TABLE IF EXISTS %s;", name)
+		}
+	}
+
+	for _, name := range sortedTables(d.source.Tables, false) {
+		s := d.source.Tables[name]
+		t, ok := d.target.Tables[name]
+		if !ok {
+			d.create(ClassTable, "%s", createTable(s))
+			d.comment("TABLE "+name, s.Comment, "")
+			for _, col := range s.Columns {
+				d.comment("COLUMN "+name+"."+col.Name, col.Comment, "")
+			}
+			continue
+		}
+
+		d.diffColumns(name, s.Columns, t.Columns)
+		d.comment("TABLE "+name, s.Comment, t.Comment)
+	}
+}
+
+func createTable(t *Table) string {
+	if t.PartitionOf != "" {
+		sql := fmt.Sprintf("CREATE TABLE %s PARTITION OF %s %s", t.Name, t.PartitionOf, t.PartitionBound)
+		if t.PartitionKey != "" {
+			sql += " PARTITION BY " + t.PartitionKey
+		}
+		return sql + ";"
+	}
+
+	cols := make([]string, len(t.Columns))
+	for i, col := range t.Columns {
+		cols[i] = "    " + columnDefinition(col)
+	}
+	sql := fmt.Sprintf("CREATE TABLE %s (\n%s\n)", t.Name, strings.Join(cols, ",\n"))
+	if t.PartitionKey != "" {
+		sql += " PARTITION BY " + t.PartitionKey
+	}
+	return sql + ";"
+}
+
+func columnDefinition(col *Column) string {
+	def := col.Name + " " + col.Type
+	if col.Collation != "" {
+		def += " COLLATE " + col.Collation
+	}
+	switch col.Identity {
+	case "a":
+		def += " GENERATED ALWAYS AS IDENTITY"
+	case "d":
+		def += " GENERATED BY DEFAULT AS IDENTITY"
+	}
+	if col.Generated != "" {
+		def += " GENERATED ALWAYS AS (" + col.Generated + ") STORED"
+	}
+	if col.Default != "" {
+		def += " DEFAULT " + col.Default
+	}
+	if col.NotNull {
+		def += " NOT NULL"
+	}
+	return def
+}
+
+func (d *differ) diffColumns(table string, source []*Column, target []*Column) {
+	sourceCols := map[string]*Column{}
+	for _, col := range source {
+		sourceCols[col.Name] = col
+	}
+	targetCols := map[string]*Column{}
+	for _, col := range target {
+		targetCols[col.Name] = col
+	}
+
+	for _, t := range target {
+		// Generated columns can't be altered, only replaced.
+		if s, ok := sourceCols[t.Name]; !ok || s.Generated != t.Generated {
+			d.drop(ClassTable, "ALTER TABLE %s DROP COLUMN IF EXISTS %s;", table, t.Name)
+		}
+	}
+
+	for _, s := range source {
+		t, ok := targetCols[s.Name]
+		if !ok || s.Generated != t.Generated {
+			d.create(ClassTable, "ALTER TABLE %s ADD COLUMN %s;", table, columnDefinition(s))
+			d.comment("COLUMN "+table+"."+s.Name, s.Comment, "")
+			continue
+		}
+
+		alter := "ALTER TABLE " + table + " ALTER COLUMN " + s.Name
+		typeChanged := s.Type != t.Type || s.Collation != t.Collation
+		defaultChanged := s.Default != t.Default
+		// The old default may not be castable to the new type.
+		if t.Default != "" && (defaultChanged || typeChanged) {
+			d.create(ClassTable, "%s DROP DEFAULT;", alter)
+		}
+		if typeChanged {
+			collate := ""
+			if s.Collation != "" {
+				collate = " COLLATE " + s.Collation
+			}
+			d.create(ClassTable, "%s TYPE %s%s USING %s::%s;", alter, s.Type, collate, s.Name, s.Type)
+		}
+		if s.NotNull && !t.NotNull {
+			d.create(ClassTable, "%s SET NOT NULL;", alter)
+		} else if !s.NotNull && t.NotNull && s.Identity == "" {
+			d.create(ClassTable, "%s DROP NOT NULL;", alter)
+		}
+		if s.Identity != t.Identity {
+			generated := map[string]string{"a": "ALWAYS", "d": "BY DEFAULT"}
+			if t.Identity == "" {
+				d.create(ClassTable, "%s ADD GENERATED %s AS IDENTITY;", alter, generated[s.Identity])
+			} else if s.Identity == "" {
+				d.create(ClassTable, "%s DROP IDENTITY IF EXISTS;", alter)
+			} else {
+				d.create(ClassTable, "%s SET GENERATED %s;", alter, generated[s.Identity])
+			}
+		}
+		if s.Default != "" && (defaultChanged || typeChanged) {
+			d.create(ClassTable, "%s SET DEFAULT %s;", alter, s.Default)
+		}
+		d.comment("COLUMN "+table+"."+s.Name, s.Comment, t.Comment)
+	}
+}
+
+func (d *differ) diffConstraints() {
+	// Foreign keys are dropped first and added last, since they depend on
+	// the other constraints (primary keys, unique constraints) of the
+	// referenced table. They're also dropped along with dropped tables, in
+	// case they reference each other.
+	for _, fkeys := range []bool{true, false} {
+		for _, key := range sortedKeys(d.target.Constraints) {
+			t := d.target.Constraints[key]
+			if (t.Type == "f") != fkeys {
+				continue
+			}
+			if _, ok := d.source.Tables[t.Table]; !ok && !fkeys {
+				continue
+			}
+			if s, ok := d.source.Constraints[key]; !ok || s.Type != t.Type || s.Definition != t.Definition {
+				d.drop(ClassConstraint, "ALTER TABLE %s DROP CONSTRAINT IF EXISTS %s;", t.Table, t.Name)
+			}
+		}
+	}
+
+	for _, fkeys := range []bool{false, true} {
+		for _, key := range sortedKeys(d.source.Constraints) {
+			s := d.source.Constraints[key]
+			if (s.Type == "f") != fkeys {
+				continue
+			}
+			var targetComment string
+			if t, ok := d.target.Constraints[key]; !ok || s.Type != t.Type || s.Definition != t.Definition {
+				d.create(ClassConstraint, "ALTER TABLE %s ADD CONSTRAINT %s %s;", s.Table, s.Name, s.Definition)
+			} else {
+				targetComment = t.Comment
+			}
+			d.comment("CONSTRAINT "+s.Name+" ON "+s.Table, s.Comment, targetComment)
+		}
+	}
+}
+
+func (d *differ) diffIndexes() {
+	for _, name := range sortedKeys(d.target.Indexes) {
+		t := d.target.Indexes[name]
+		if !d.sourceHasRelation(t.Table) {
+			continue
+		}
+		if s, ok := d.source.Indexes[name]; !ok || s.Definition != t.Definition {
+			d.drop(ClassIndex, "DROP INDEX IF EXISTS %s;", name)
+		}
+	}
+
+	for _, name := range sortedKeys(d.source.Indexes) {
+		s := d.source.Indexes[name]
+		var targetComment string
+		if t, ok := d.target.Indexes[name]; !ok || s.Definition != t.Definition || d.recreated["TABLE "+s.Table] {
+			d.create(ClassIndex, "%s", terminate(s.Definition))
+		} else {
+			targetComment = t.Comment
+		}
+		d.comment("INDEX "+name, s.Comment, targetComment)
+	}
+}
+
+func (d *differ) diffViews() {
+	// Materialized views can't be replaced in place.
+	replaced := func(s *View, t *View) bool {
+		return s.Materialized != t.Materialized || (s.Materialized && (s.Definition != t.Definition || s.Options != t.Options))
+	}
+
+	targetOrder := sortedViews(d.target.Views)
+	for i := len(targetOrder) - 1; i >= 0; i-- {
+		t := d.target.Views[targetOrder[i]]
+		if s, ok := d.source.Views[t.Name]; !ok || replaced(s, t) {
+			d.drop(ClassView, "DROP %s IF EXISTS %s;", viewKind(t), t.Name)
+		}
+	}
+
+	for _, name := range sortedViews(d.source.Views) {
+		s := d.source.Views[name]
+		var targetComment string
+		t, ok := d.target.Views[name]
+		if ok && !replaced(s, t) {
+			targetComment = t.Comment
+		} else if ok {
+			d.recreated["TABLE "+name] = true
+		}
+
+		if !ok || replaced(s, t) || s.Definition != t.Definition || s.Options != t.Options {
+			with := ""
+			if s.Options != "" {
+				with = " WITH (" + s.Options + ")"
+			}
+			if s.Materialized {
+				d.create(ClassView, "CREATE MATERIALIZED VIEW %s%s AS\n%s", name, with, terminate(s.Definition))
+			} else {
+				d.create(ClassView, "CREATE OR REPLACE VIEW %s%s AS\n%s", name, with, terminate(s.Definition))
+			}
+		}
+		d.comment(viewKind(s)+" "+name, s.Comment, targetComment)
+	}
+}
+
+func viewKind(v *View) string {
+	if v.Materialized {
+		return "MATERIALIZED VIEW"
+	}
+	return "VIEW"
+}
+
+func (d *differ) diffTriggers() {
+	for _, key := range sortedKeys(d.target.Triggers) {
+		t := d.target.Triggers[key]
+		if !d.sourceHasRelation(t.Table) {
+			continue
+		}
+		if s, ok := d.source.Triggers[key]; !ok || s.Definition != t.Definition {
+			d.drop(ClassTrigger, "DROP TRIGGER IF EXISTS %s ON %s;", t.Name, t.Table)
+		}
+	}
+
+	for _, key := range sortedKeys(d.source.Triggers) {
+		s := d.source.Triggers[key]
+		var targetComment string
+		if t, ok := d.target.Triggers[key]; !ok || s.Definition != t.Definition || d.recreated["TABLE "+s.Table] {
+			d.create(ClassTrigger, "%s", terminate(s.Definition))
+		} else {
+			targetComment = t.Comment
+		}
+		d.comment("TRIGGER "+s.Name+" ON "+s.Table, s.Comment, targetComment)
+	}
+}
+
+func (d *differ) diffPolicies() {
+	// Policies only apply once row level security is enabled.
+	for _, name := range sortedKeys(d.source.Tables) {
+		s := d.source.Tables[name]
+		var rowSecurity, forceRowSecurity bool
+		if t, ok := d.target.Tables[name]; ok {
+			rowSecurity, forceRowSecurity = t.RowSecurity, t.ForceRowSecurity
+		}
+
+		if s.RowSecurity && !rowSecurity {
+			d.create(ClassPolicy, "ALTER TABLE %s ENABLE ROW LEVEL SECURITY;", name)
+		} else if !s.RowSecurity && rowSecurity {
+			d.create(ClassPolicy, "ALTER TABLE %s DISABLE ROW LEVEL SECURITY;", name)
+		}
+		if s.ForceRowSecurity && !forceRowSecurity {
+			d.create(ClassPolicy, "ALTER TABLE %s FORCE ROW LEVEL SECURITY;", name)
+		} else if !s.ForceRowSecurity && forceRowSecurity {
+			d.create(ClassPolicy, "ALTER TABLE %s NO FORCE ROW LEVEL SECURITY;", name)
+		}
+	}
+
+	for _, key := range sortedKeys(d.target.Policies) {
+		t := d.target.Policies[key]
+		if !d.sourceHasRelation(t.Table) {
+			continue
+		}
+		if s, ok := d.source.Policies[key]; !ok || createPolicy(s) != createPolicy(t) {
+			d.drop(ClassPolicy, "DROP POLICY IF EXISTS %s ON %s;", t.Name, t.Table)
+		}
+	}
+
+	for _, key := range sortedKeys(d.source.Policies) {
+		s := d.source.Policies[key]
+		var targetComment string
+		if t, ok := d.target.Policies[key]; !ok || createPolicy(s) != createPolicy(t) {
+			d.create(ClassPolicy, "%s", createPolicy(s))
+		} else {
+			targetComment = t.Comment
+		}
+		d.comment("POLICY "+s.Name+" ON "+s.Table, s.Comment, targetComment)
+	}
+}
+
+func createPolicy(p *Policy) string {
+	permissive := "PERMISSIVE"
+	if !p.Permissive {
+		permissive = "RESTRICTIVE"
+	}
+	sql := fmt.Sprintf("CREATE POLICY %s ON %s AS %s FOR %s TO %s", p.Name, p.Table, permissive, p.Command, strings.Join(p.Roles, ", "))
+	if p.Using != "" {
+		sql += " USING (" + p.Using + ")"
+	}
+	if p.WithCheck != "" {
+		sql += " WITH CHECK (" + p.WithCheck + ")"
+	}
+	return sql + ";"
+}
+
+func (d *differ) diffAcls() {
+	keys := sortedKeys(d.source.Acls)
+	for _, key := range sortedKeys(d.target.Acls) {
+		if _, ok := d.source.Acls[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		var objectType, object string
+		var source, defaults []Privilege
+		if s, ok := d.source.Acls[key]; ok {
+			objectType, object, source, defaults = s.ObjectType, s.Object, s.Privileges, s.Defaults
+		} else {
+			t := d.target.Acls[key]
+			objectType, object = t.ObjectType, t.Object
+		}
+
+		if !d.source.hasObject(objectType, object) {
+			continue
+		}
+		// New and recreated objects start out with the default privileges.
+		baseline := defaults
+		if d.target.hasObject(objectType, object) && !d.recreated[key] {
+			baseline = nil
+			if t, ok := d.target.Acls[key]; ok {
+				baseline = t.Privileges
+			}
+		}
+
+		on := objectType + " " + object
+		for _, revoke := range groupPrivileges(subtractPrivileges(baseline, source)) {
+			d.create(ClassGrant, "REVOKE %s ON %s FROM %s;", strings.Join(revoke.types, ", "), on, revoke.grantee)
+		}
+		for _, grant := range groupPrivileges(subtractPrivileges(source, baseline)) {
+			withGrantOption := ""
+			if grant.grantable {
+				withGrantOption = " WITH GRANT OPTION"
+			}
+			d.create(ClassGrant, "GRANT %s ON %s TO %s%s;", strings.Join(grant.types, ", "), on, grant.grantee, withGrantOption)
+		}
+	}
+}
+
+// subtractPrivileges returns the privileges in a that are not in b.
+func subtractPrivileges(a []Privilege, b []Privilege) []Privilege {
+	result := []Privilege{}
+	for _, p := range a {
+		found := false
+		for _, q := range b {
+			if p == q {
+				found = true
+				break
+			}
+		}
+		if !found {
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
+type privilegeGroup struct {
+	grantee   string
+	grantable bool
+	types     []string
+}
+
+// groupPrivileges groups privileges by grantee, so each grantee gets a single
+// GRANT or REVOKE per object.
+func groupPrivileges(privileges []Privilege) []privilegeGroup {
+	groups := []privilegeGroup{}
+	for _, p := range privileges {
+		i := 0
+		for ; i < len(groups); i++ {
+			if groups[i].grantee == p.Grantee && groups[i].grantable == p.Grantable {
+				break
+			}
+		}
+		if i == len(groups) {
+			groups = append(groups, privilegeGroup{grantee: p.Grantee, grantable: p.Grantable})
+		}
+		groups[i].types = append(groups[i].types, p.Type)
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		return groups[i].grantee < groups[j].grantee
+	})
+	for _, group := range groups {
+		sort.Strings(group.types)
+	}
+	return groups
+}
+
+func (d *differ) diffPublications() {
+	for _, name := range sortedKeys(d.target.Publications) {
+		t := d.target.Publications[name]
+		if s, ok := d.source.Publications[name]; !ok || s.AllTables != t.AllTables {
+			d.drop(ClassPublication, "DROP PUBLICATION IF EXISTS %s;", name)
+		}
+	}
+
+	for _, name := range sortedKeys(d.source.Publications) {
+		s := d.source.Publications[name]
+		t, ok := d.target.Publications[name]
+		if !ok || s.AllTables != t.AllTables {
+			forTables := ""
+			if s.AllTables {
+				forTables = " FOR ALL TABLES"
+			} else if len(s.Tables) > 0 {
+				forTables = " FOR TABLE " + strings.Join(s.Tables, ", ")
+			}
+			d.create(ClassPublication, "CREATE PUBLICATION %s%s WITH (publish = %s);", name, forTables, quoteLiteral(s.Publish))
+			continue
+		}
+
+		if s.Publish != t.Publish {
+			d.create(ClassPublication, "ALTER PUBLICATION %s SET (publish = %s);", name, quoteLiteral(s.Publish))
+		}
+		for _, table := range t.Tables {
+			// Dropped tables leave the publication on their own.
+			if !contains(s.Tables, table) && d.sourceHasRelation(table) {
+				d.create(ClassPublication, "ALTER PUBLICATION %s DROP TABLE %s;", name, table)
+			}
+		}
+		for _, table := range s.Tables {
+			if !contains(t.Tables, table) {
+				d.create(ClassPublication, "ALTER PUBLICATION %s ADD TABLE %s;", name, table)
+			}
+		}
+	}
+}
+
+func (d *differ) sourceHasRelation(name string) bool {
+	if _, ok := d.source.Tables[name]; ok {
+		return true
+	}
+	_, ok := d.source.Views[name]
+	return ok
+}
+
+func (c *Catalog) hasObject(objectType string, name string) bool {
+	var ok bool
+	switch objectType {
+	case "TABLE":
+		if _, ok = c.Tables[name]; !ok {
+			_, ok = c.Views[name]
+		}
+	case "SEQUENCE":
+		_, ok = c.Sequences[name]
+	case "FUNCTION", "PROCEDURE":
+		_, ok = c.Functions[name]
+	case "SCHEMA":
+		_, ok = c.Schemas[name]
+	}
+	return ok
+}
+
+// sortedTables returns tables ordered so that partitions come after the
+// tables they're partitions of, or before if reverse is set.
+func sortedTables(tables map[string]*Table, reverse bool) []string {
+	depth := func(name string) int {
+		n := 0
+		for t, ok := tables[name]; ok && t.PartitionOf != ""; t, ok = tables[t.PartitionOf] {
+			n++
+		}
+		return n
+	}
+
+	names := sortedKeys(tables)
+	sort.SliceStable(names, func(i, j int) bool {
+		if reverse {
+			return depth(names[i]) > depth(names[j])
+		}
+		return depth(names[i]) < depth(names[j])
+	})
+	return names
+}
+
+// sortedViews returns views ordered so that each view comes after the views it
+// depends on. Ties are broken by name.
+func sortedViews(views map[string]*View) []string {
+	order := []string{}
+	visited := map[string]bool{}
+
+	var visit func(name string)
+	visit = func(name string) {
+		v, ok := views[name]
+		if !ok || visited[name] {
+			return
+		}
+		visited[name] = true
+
+		deps := append([]string{}, v.Dependencies...)
+		sort.Strings(deps)
+		for _, dep := range deps {
+			visit(dep)
+		}
+		order = append(order, name)
+	}
+
+	for _, name := range sortedKeys(views) {
+		visit(name)
+	}
+	return order
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func contains(s []string, e string) bool {
+	for _, a := range s {
+		if a == e {
+			return true
+		}
+	}
+	return false
+}
+
+func quoteLiteral(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+func terminate(sql string) string {
+	sql = strings.TrimSpace(sql)
+	if !strings.HasSuffix(sql, ";") {
+		sql += ";"
+	}
+	return sql
+}
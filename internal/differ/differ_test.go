@@ -0,0 +1,199 @@
+package differ
+
+import (
+	"strings"
+	"testing"
+)
+
+func newCatalog() *Catalog {
+	return &Catalog{
+		Extensions:   map[string]*Extension{},
+		Schemas:      map[string]*Schema{"public": {Name: "public"}},
+		Enums:        map[string]*Enum{},
+		Sequences:    map[string]*Sequence{},
+		Functions:    map[string]*Function{},
+		Tables:       map[string]*Table{},
+		Constraints:  map[string]*Constraint{},
+		Indexes:      map[string]*Index{},
+		Views:        map[string]*View{},
+		Triggers:     map[string]*Trigger{},
+		Policies:     map[string]*Policy{},
+		Acls:         map[string]*Acl{},
+		Publications: map[string]*Publication{},
+	}
+}
+
+func sqls(stmts []Statement) []string {
+	result := []string{}
+	for _, stmt := range stmts {
+		result = append(result, stmt.Sql)
+	}
+	return result
+}
+
+func assertStatements(t *testing.T, actual []Statement, expected []string) {
+	if strings.Join(sqls(actual), "\n") != strings.Join(expected, "\n") {
+		t.Errorf("unexpected statements:\n%s\n\nexpected:\n%s", strings.Join(sqls(actual), "\n"), strings.Join(expected, "\n"))
+	}
+}
+
+func TestDiff(t *testing.T) {
+	t.Run("identical catalogs", func(t *testing.T) {
+		assertStatements(t, Diff(newCatalog(), newCatalog()), []string{})
+	})
+
+	t.Run("creates objects in dependency order", func(t *testing.T) {
+		source := newCatalog()
+		source.Enums["public.mood"] = &Enum{Name: "public.mood", Labels: []string{"happy", "sad"}}
+		source.Tables["public.profiles"] = &Table{Name: "public.profiles", Columns: []*Column{
+			{Name: "id", Type: "uuid", NotNull: true},
+			{Name: "mood", Type: "public.mood", Comment: "How they feel"},
+		}}
+		source.Tables["public.posts"] = &Table{Name: "public.posts", Columns: []*Column{
+			{Name: "id", Type: "bigint", NotNull: true, Identity: "a"},
+			{Name: "author", Type: "uuid"},
+		}}
+		source.Constraints["public.profiles profiles_pkey"] = &Constraint{Table: "public.profiles", Name: "profiles_pkey", Type: "p", Definition: "PRIMARY KEY (id)"}
+		source.Constraints["public.posts posts_author_fkey"] = &Constraint{Table: "public.posts", Name: "posts_author_fkey", Type: "f", Definition: "FOREIGN KEY (author) REFERENCES public.profiles(id)"}
+		source.Views["public.b"] = &View{Name: "public.b", Definition: " SELECT * FROM public.a;", Dependencies: []string{"public.a"}}
+		source.Views["public.a"] = &View{Name: "public.a", Definition: " SELECT * FROM public.posts;"}
+		source.Policies["public.posts read"] = &Policy{Table: "public.posts", Name: "read", Permissive: true, Command: "SELECT", Roles: []string{"PUBLIC"}, Using: "true"}
+		source.Acls["TABLE public.posts"] = &Acl{ObjectType: "TABLE", Object: "public.posts", Privileges: []Privilege{{Grantee: "anon", Type: "SELECT"}, {Grantee: "anon", Type: "INSERT"}}}
+
+		assertStatements(t, Diff(source, newCatalog()), []string{
+			"CREATE TYPE public.mood AS ENUM ('happy', 'sad');",
+			"CREATE TABLE public.posts (\n    id bigint GENERATED ALWAYS AS IDENTITY NOT NULL,\n    author uuid\n);",
+			"CREATE TABLE public.profiles (\n    id uuid NOT NULL,\n    mood public.mood\n);",
+			"ALTER TABLE public.profiles ADD CONSTRAINT profiles_pkey PRIMARY KEY (id);",
+			"ALTER TABLE public.posts ADD CONSTRAINT posts_author_fkey FOREIGN KEY (author) REFERENCES public.profiles(id);",
+			"CREATE OR REPLACE VIEW public.a AS\nSELECT * FROM public.posts;",
+			"CREATE OR REPLACE VIEW public.b AS\nSELECT * FROM public.a;",
+			"CREATE POLICY read ON public.posts AS PERMISSIVE FOR SELECT TO PUBLIC USING (true);",
+			"GRANT INSERT, SELECT ON TABLE public.posts TO anon;",
+			"COMMENT ON COLUMN public.profiles.mood IS 'How they feel';",
+		})
+	})
+
+	t.Run("sets sequence owners after creating tables", func(t *testing.T) {
+		source := newCatalog()
+		source.Sequences["public.t_id_seq"] = &Sequence{Name: "public.t_id_seq", DataType: "integer", Start: 1, Increment: 1, Min: 1, Max: 2147483647, Cache: 1, OwnedBy: "public.t.id"}
+		source.Tables["public.t"] = &Table{Name: "public.t", Columns: []*Column{
+			{Name: "id", Type: "integer", NotNull: true, Default: "nextval('public.t_id_seq'::regclass)"},
+		}}
+
+		assertStatements(t, Diff(source, newCatalog()), []string{
+			"CREATE SEQUENCE IF NOT EXISTS public.t_id_seq AS integer START WITH 1 INCREMENT BY 1 MINVALUE 1 MAXVALUE 2147483647 CACHE 1 NO CYCLE;",
+			"CREATE TABLE public.t (\n    id integer DEFAULT nextval('public.t_id_seq'::regclass) NOT NULL\n);",
+			"ALTER SEQUENCE public.t_id_seq OWNED BY public.t.id;",
+		})
+	})
+
+	t.Run("creates functions using new tables after the tables", func(t *testing.T) {
+		source := newCatalog()
+		source.Tables["public.todos"] = &Table{Name: "public.todos", Columns: []*Column{
+			{Name: "id", Type: "bigint", Default: "public.next_id()"},
+		}}
+		source.Functions["public.next_id()"] = &Function{Name: "public.next_id()", Kind: "FUNCTION", Result: "bigint", Definition: "CREATE OR REPLACE FUNCTION public.next_id()\n RETURNS bigint\n LANGUAGE sql\nAS $function$ select 1 $function$\n"}
+		source.Functions["public.all_todos()"] = &Function{Name: "public.all_todos()", Kind: "FUNCTION", Result: "SETOF todos", Definition: "CREATE OR REPLACE FUNCTION public.all_todos()\n RETURNS SETOF todos\n LANGUAGE sql\nAS $function$ select * from public.todos $function$\n"}
+
+		assertStatements(t, Diff(source, newCatalog()), []string{
+			"CREATE OR REPLACE FUNCTION public.next_id()\n RETURNS bigint\n LANGUAGE sql\nAS $function$ select 1 $function$;",
+			"CREATE TABLE public.todos (\n    id bigint DEFAULT public.next_id()\n);",
+			"CREATE OR REPLACE FUNCTION public.all_todos()\n RETURNS SETOF todos\n LANGUAGE sql\nAS $function$ select * from public.todos $function$;",
+		})
+	})
+
+	t.Run("drops functions using dropped tables before the tables", func(t *testing.T) {
+		target := newCatalog()
+		target.Tables["public.todos"] = &Table{Name: "public.todos", Columns: []*Column{{Name: "id", Type: "bigint"}}}
+		target.Functions["public.count_todos(t public.todos)"] = &Function{Name: "public.count_todos(t public.todos)", Kind: "FUNCTION", Result: "bigint", Definition: "CREATE OR REPLACE FUNCTION public.count_todos(t public.todos)\n RETURNS bigint\n LANGUAGE sql\nAS $function$ select 1 $function$\n"}
+
+		assertStatements(t, Diff(newCatalog(), target), []string{
+			"DROP FUNCTION IF EXISTS public.count_todos(t public.todos);",
+			"DROP TABLE IF EXISTS public.todos;",
+		})
+	})
+
+	t.Run("drops objects in reverse dependency order", func(t *testing.T) {
+		target := newCatalog()
+		target.Tables["public.posts"] = &Table{Name: "public.posts", Columns: []*Column{{Name: "id", Type: "bigint"}}}
+		target.Indexes["public.posts_idx"] = &Index{Name: "public.posts_idx", Table: "public.posts", Definition: "CREATE INDEX posts_idx ON public.posts USING btree (id)"}
+		target.Views["public.b"] = &View{Name: "public.b", Definition: " SELECT * FROM public.a;", Dependencies: []string{"public.a"}}
+		target.Views["public.a"] = &View{Name: "public.a", Definition: " SELECT * FROM public.posts;"}
+
+		assertStatements(t, Diff(newCatalog(), target), []string{
+			"DROP VIEW IF EXISTS public.b;",
+			"DROP VIEW IF EXISTS public.a;",
+			"DROP TABLE IF EXISTS public.posts;",
+		})
+	})
+
+	t.Run("alters existing columns, enums and privileges", func(t *testing.T) {
+		source := newCatalog()
+		source.Enums["public.mood"] = &Enum{Name: "public.mood", Labels: []string{"happy", "ok", "sad"}}
+		source.Tables["public.t"] = &Table{Name: "public.t", Columns: []*Column{
+			{Name: "a", Type: "bigint", NotNull: true, Default: "0"},
+			{Name: "c", Type: "text"},
+		}}
+		source.Acls["TABLE public.t"] = &Acl{ObjectType: "TABLE", Object: "public.t", Privileges: []Privilege{{Grantee: "anon", Type: "SELECT"}}}
+
+		target := newCatalog()
+		target.Enums["public.mood"] = &Enum{Name: "public.mood", Labels: []string{"happy", "sad"}}
+		target.Tables["public.t"] = &Table{Name: "public.t", Columns: []*Column{
+			{Name: "a", Type: "integer", Default: "1"},
+			{Name: "b", Type: "text"},
+		}}
+		target.Acls["TABLE public.t"] = &Acl{ObjectType: "TABLE", Object: "public.t", Privileges: []Privilege{{Grantee: "anon", Type: "SELECT"}, {Grantee: "anon", Type: "DELETE"}}}
+
+		assertStatements(t, Diff(source, target), []string{
+			"ALTER TABLE public.t DROP COLUMN IF EXISTS b;",
+			"ALTER TYPE public.mood ADD VALUE 'ok' AFTER 'happy';",
+			"ALTER TABLE public.t ALTER COLUMN a DROP DEFAULT;",
+			"ALTER TABLE public.t ALTER COLUMN a TYPE bigint USING a::bigint;",
+			"ALTER TABLE public.t ALTER COLUMN a SET NOT NULL;",
+			"ALTER TABLE public.t ALTER COLUMN a SET DEFAULT 0;",
+			"ALTER TABLE public.t ADD COLUMN c text;",
+			"REVOKE DELETE ON TABLE public.t FROM anon;",
+		})
+	})
+
+	t.Run("enables row level security before creating policies", func(t *testing.T) {
+		source := newCatalog()
+		source.Tables["public.t"] = &Table{Name: "public.t", RowSecurity: true, Columns: []*Column{{Name: "a", Type: "text"}}}
+		source.Policies["public.t read"] = &Policy{Table: "public.t", Name: "read", Permissive: true, Command: "SELECT", Roles: []string{"authenticated"}, Using: "true"}
+
+		target := newCatalog()
+		target.Tables["public.t"] = &Table{Name: "public.t", Columns: []*Column{{Name: "a", Type: "text"}}}
+		target.Policies["public.t write"] = &Policy{Table: "public.t", Name: "write", Permissive: true, Command: "INSERT", Roles: []string{"PUBLIC"}, WithCheck: "true"}
+
+		assertStatements(t, Diff(source, target), []string{
+			"DROP POLICY IF EXISTS write ON public.t;",
+			"ALTER TABLE public.t ENABLE ROW LEVEL SECURITY;",
+			"CREATE POLICY read ON public.t AS PERMISSIVE FOR SELECT TO authenticated USING (true);",
+		})
+	})
+}
+
+func TestFormat(t *testing.T) {
+	out := string(Format([]Statement{
+		{Class: ClassPolicy, Drop: true, Sql: "DROP POLICY IF EXISTS write ON public.t;"},
+		{Class: ClassPolicy, Sql: "ALTER TABLE public.t ENABLE ROW LEVEL SECURITY;"},
+		{Class: ClassPolicy, Sql: "CREATE POLICY read ON public.t AS PERMISSIVE FOR SELECT TO authenticated USING (true);"},
+		{Class: ClassGrant, Sql: "GRANT SELECT ON TABLE public.t TO anon;"},
+	}))
+
+	expected := `-- Drop row level security policies
+DROP POLICY IF EXISTS write ON public.t;
+
+-- Row level security policies
+ALTER TABLE public.t ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY read ON public.t AS PERMISSIVE FOR SELECT TO authenticated USING (true);
+
+-- Grants
+GRANT SELECT ON TABLE public.t TO anon;
+`
+	if !strings.HasSuffix(out, expected) {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+}
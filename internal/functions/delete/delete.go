@@ -1,18 +1,20 @@
 package delete
 
 import (
+	"context"
 	"errors"
 	"fmt"
-	"io"
-	"net/http"
 	"os"
 	"regexp"
 	"strings"
 
+	"github.com/supabase/cli/internal/api"
 	"github.com/supabase/cli/internal/login"
 	"github.com/supabase/cli/internal/utils"
 )
 
+var ctx = context.Background()
+
 func Run(slug string, projectRefArg string) error {
 	// 1. Sanity checks.
 	{
@@ -58,51 +60,19 @@ func Run(slug string, projectRefArg string) error {
 			projectRef = projectRefArg
 		}
 
-		accessToken, err := utils.LoadAccessToken()
-		if err != nil {
-			return err
-		}
-
-		req, err := http.NewRequest("GET", "https://api.supabase.io/v1/projects/"+projectRef+"/functions/"+slug, nil)
+		client, err := api.NewDefaultClient()
 		if err != nil {
 			return err
 		}
-		req.Header.Add("Authorization", "Bearer "+string(accessToken))
-		resp, err := http.DefaultClient.Do(req)
-		if err != nil {
-			return err
-		}
-		defer resp.Body.Close()
 
-		switch resp.StatusCode {
-		case http.StatusNotFound: // Function doesn't exist
+		if _, err := client.GetFunction(ctx, projectRef, slug); api.IsNotFound(err) {
 			return errors.New("Function " + utils.Aqua(slug) + " does not exist on the Supabase project.")
-		case http.StatusOK: // Function exists
-			req, err := http.NewRequest("DELETE", "https://api.supabase.io/v1/projects/"+projectRef+"/functions/"+slug, nil)
-			if err != nil {
-				return err
-			}
-			req.Header.Add("Authorization", "Bearer "+string(accessToken))
-			resp, err := http.DefaultClient.Do(req)
-			if err != nil {
-				return err
-			}
-			defer resp.Body.Close()
-			if resp.StatusCode != 200 {
-				body, err := io.ReadAll(resp.Body)
-				if err != nil {
-					return fmt.Errorf("Failed to delete Function %v on the Supabase project: %w", utils.Aqua(slug), err)
-				}
-
-				return errors.New("Failed to delete Function " + utils.Aqua(slug) + " on the Supabase project: " + string(body))
-			}
-		default:
-			body, err := io.ReadAll(resp.Body)
-			if err != nil {
-				return fmt.Errorf("Unexpected error deleting Function: %w", err)
-			}
+		} else if err != nil {
+			return fmt.Errorf("Unexpected error deleting Function: %w", err)
+		}
 
-			return errors.New("Unexpected error deleting Function: " + string(body))
+		if err := client.DeleteFunction(ctx, projectRef, slug); err != nil {
+			return fmt.Errorf("Failed to delete Function %v on the Supabase project: %w", utils.Aqua(slug), err)
 		}
 	}
 
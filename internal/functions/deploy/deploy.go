@@ -3,24 +3,49 @@ package deploy
 import (
 	"bufio"
 	"bytes"
-	"encoding/json"
+	"context"
 	"errors"
 	"fmt"
-	"io"
-	"net/http"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"regexp"
 	"runtime"
 	"strings"
+	"sync"
 
+	"github.com/charmbracelet/glamour"
+	"github.com/supabase/cli/internal/api"
 	"github.com/supabase/cli/internal/login"
 	"github.com/supabase/cli/internal/utils"
 )
 
-func Run(slug string, projectRefArg string, verifyJWT bool) error {
+var ctx = context.Background()
+
+const (
+	statusCreated = "created"
+	statusUpdated = "updated"
+	statusSkipped = "skipped"
+	statusFailed  = "failed"
+)
+
+// The outcome of deploying a Function.
+type result struct {
+	slug    string
+	status  string
+	version int
+	id      string
+	err     error
+}
+
+// Run deploys the Function `slug`, or every local Function if slug is empty.
+// Functions are bundled in parallel, and ones whose bundle and verify_jwt match
+// the deployed Function are skipped. verifyJWT overrides `verify_jwt` in
+// supabase.toml if set. Bundles are linted for eval() and the Function
+// constructor unless noLint is set.
+func Run(slug string, projectRefArg string, verifyJWT *bool, noLint bool) error {
 	// 1. Sanity checks.
+	var slugs []string
 	{
 		if _, err := utils.LoadAccessToken(); err != nil && strings.HasPrefix(err.Error(), "Access token not provided. Supply an access token by running") {
 			if err := login.Run(); err != nil {
@@ -63,174 +88,270 @@ Enter your project ref: `)
 		if err := utils.InstallOrUpgradeDeno(); err != nil {
 			return err
 		}
-		if err := utils.ValidateFunctionSlug(slug); err != nil {
+		if err := utils.LoadConfig(); err != nil {
 			return err
 		}
-	}
 
-	// 2. Bundle Function.
-	var newFunctionBody string
-	{
-		fmt.Println("Bundling " + utils.Bold(slug))
-
-		home, err := os.UserHomeDir()
-		if err != nil {
-			return err
-		}
-		denoBinName := "deno"
-		if runtime.GOOS == "windows" {
-			denoBinName = "deno.exe"
+		if slug != "" {
+			if err := utils.ValidateFunctionSlug(slug); err != nil {
+				return err
+			}
+			slugs = []string{slug}
+		} else {
+			localSlugs, err := utils.ListFunctionSlugs()
+			if err != nil {
+				return err
+			}
+			if len(localSlugs) == 0 {
+				return errors.New("No Functions found in " + utils.Bold(filepath.Join(utils.Config.Edgefunctions.SrcPath, utils.Config.Edgefunctions.FunctionsPath)) + ".")
+			}
+			slugs = localSlugs
 		}
-		denoPath := filepath.Join(home, ".supabase", denoBinName)
+	}
 
-		cwd, err := os.Getwd()
+	// --project-ref overrides value on disk
+	projectRef := projectRefArg
+	if len(projectRef) == 0 {
+		projectRefBytes, err := os.ReadFile(".supabase/temp/project-ref")
 		if err != nil {
 			return err
 		}
+		projectRef = string(projectRefBytes)
+	}
 
-		if err := utils.LoadConfig(); err != nil {
-			return err
+	client, err := api.NewDefaultClient()
+	if err != nil {
+		return err
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return err
+	}
+	denoBinName := "deno"
+	if runtime.GOOS == "windows" {
+		denoBinName = "deno.exe"
+	}
+	denoPath := filepath.Join(home, ".supabase", denoBinName)
+
+	warnMissingSecrets(client, projectRef, slugs)
+
+	// 2. Deploy Functions, bundling up to one per CPU at a time.
+	results := make([]result, len(slugs))
+	{
+		sem := make(chan struct{}, runtime.NumCPU())
+		var wg sync.WaitGroup
+		for i, slug := range slugs {
+			wg.Add(1)
+			go func(i int, slug string) {
+				defer wg.Done()
+				sem <- struct{}{}
+				defer func() { <-sem }()
+
+				functionVerifyJWT := *utils.GetFunctionConfig(slug).VerifyJWT
+				if verifyJWT != nil {
+					functionVerifyJWT = *verifyJWT
+				}
+				results[i] = deployFunction(client, denoPath, projectRef, slug, functionVerifyJWT, noLint)
+			}(i, slug)
 		}
+		wg.Wait()
+	}
 
-		functionPath := filepath.Join(cwd, utils.Config.Edgefunctions.SrcPath, utils.Config.Edgefunctions.FunctionsPath, slug)
+	// 3. Report.
+	if slug != "" {
+		result := results[0]
+		switch result.status {
+		case statusFailed:
+			return result.err
+		case statusSkipped:
+			fmt.Println("Function " + utils.Aqua(slug) + " is already up to date on project " + utils.Aqua(projectRef))
+		default:
+			fmt.Println("Deployed Function " + utils.Aqua(slug) + " on project " + utils.Aqua(projectRef))
 
-		// fmt.Println("functionPath: " + functionPath)
-		if _, err := os.Stat(functionPath); errors.Is(err, os.ErrNotExist) {
-			return errors.New("Function " + utils.Aqua(functionPath) + " does not exist.")
+			url := fmt.Sprintf("https://app.supabase.io/project/%v/functions/%v/details", projectRef, result.id)
+			fmt.Println("You can inspect your deployment in the Dashboard: " + url)
 		}
+		return nil
+	}
 
-		cmd := exec.Command(denoPath, "bundle", "--quiet", functionPath+"/index.ts")
-		var outBuf, errBuf bytes.Buffer
-		cmd.Stdout = &outBuf
-		cmd.Stderr = &errBuf
-		if err := cmd.Run(); err != nil {
-			return fmt.Errorf("Error bundling function: %w\n%v", err, errBuf.String())
+	failed := 0
+	for _, result := range results {
+		if result.status == statusFailed {
+			failed++
+			fmt.Fprintln(os.Stderr, "Error deploying "+utils.Aqua(result.slug)+": "+result.err.Error())
 		}
+	}
 
-		newFunctionBody = outBuf.String()
-		//fmt.Println(newFunctionBody)
+	if err := printSummary(results); err != nil {
+		return err
 	}
 
-	// 3. Lint Function.
-	{
-		fmt.Println("Linting " + utils.Bold(slug))
+	if failed > 0 {
+		return fmt.Errorf("%d of %d Functions failed to deploy.", failed, len(results))
+	}
+	fmt.Println("Finished " + utils.Aqua("supabase functions deploy") + " on project " + utils.Aqua(projectRef) + ".")
+	return nil
+}
 
-		r := strings.NewReader(newFunctionBody)
-		cmd := exec.Command("eslint", "--no-eslintrc", "--stdin", "--parser-options", `sourceType:module,ecmaVersion:2022`, "--rule", `no-new-func:error,no-eval:error`)
+func deployFunction(client *api.Client, denoPath string, projectRef string, slug string, verifyJWT bool, noLint bool) result {
+	fmt.Println("Bundling " + utils.Bold(slug))
+	body, err := bundle(denoPath, slug)
+	if err != nil {
+		return failure(slug, err)
+	}
 
-		// eslint --no-eslintrc --stdin --parser-options "sourceType:module,ecmaVersion:2022" --rule "no-new-func:error,no-eval:error"
-		var outBuf, errBuf bytes.Buffer
-		cmd.Stdin = r
-		cmd.Stdout = &outBuf
-		cmd.Stderr = &errBuf
-		// fmt.Println(cmd.String())
-		if err := cmd.Run(); err != nil {
-			return fmt.Errorf("Error linting function: %w\n%v", err, errBuf.String())
+	if !noLint {
+		fmt.Println("Linting " + utils.Bold(slug))
+		if err := lint(body); err != nil {
+			return failure(slug, err)
 		}
 	}
 
-	// 4. Deploy new Function.
-	var projectRef string
-	var data struct {
-		Id string `json:"id"`
+	return upload(client, projectRef, slug, body, verifyJWT)
+}
+
+func bundle(denoPath string, slug string) (string, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", err
 	}
-	{
-		// --project-ref overrides value on disk
-		if len(projectRefArg) == 0 {
-			projectRefBytes, err := os.ReadFile(".supabase/temp/project-ref")
-			if err != nil {
-				return err
+
+	functionPath := filepath.Join(cwd, utils.GetFunctionPath(slug))
+
+	if _, err := os.Stat(functionPath); errors.Is(err, os.ErrNotExist) {
+		return "", errors.New("Function " + utils.Aqua(functionPath) + " does not exist.")
+	}
+
+	// Imports from the shared folder and the import map are inlined in the
+	// bundle.
+	args := []string{"bundle", "--quiet"}
+	if importMap := utils.GetFunctionImportMap(slug); importMap != "" {
+		args = append(args, "--import-map="+filepath.Join(cwd, importMap))
+	}
+	args = append(args, filepath.Join(functionPath, utils.GetFunctionConfig(slug).Entrypoint))
+
+	cmd := exec.Command(denoPath, args...)
+	var outBuf, errBuf bytes.Buffer
+	cmd.Stdout = &outBuf
+	cmd.Stderr = &errBuf
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("Error bundling function: %w\n%v", err, errBuf.String())
+	}
+
+	return outBuf.String(), nil
+}
+
+// warnMissingSecrets warns about `secrets` declared in supabase.toml for the
+// Functions that aren't set on the project. They're only a warning since the
+// Function may not need them in every environment.
+func warnMissingSecrets(client *api.Client, projectRef string, slugs []string) {
+	var declared []string
+	for _, slug := range slugs {
+		declared = append(declared, utils.GetFunctionConfig(slug).Secrets...)
+	}
+	if len(declared) == 0 {
+		return
+	}
+
+	secrets, err := client.ListSecrets(ctx, projectRef)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Warning: failed to list secrets of project "+utils.Aqua(projectRef)+": "+err.Error())
+		return
+	}
+	set := map[string]bool{}
+	for _, secret := range secrets {
+		set[secret.Name] = true
+	}
+	for _, slug := range slugs {
+		var missing []string
+		for _, name := range utils.GetFunctionConfig(slug).Secrets {
+			if !set[name] {
+				missing = append(missing, name)
 			}
-			projectRef = string(projectRefBytes)
-		} else {
-			projectRef = projectRefArg
 		}
-
-		accessToken, err := utils.LoadAccessToken()
-		if err != nil {
-			return err
+		if len(missing) > 0 {
+			fmt.Fprintln(os.Stderr, "Warning: "+utils.Aqua(slug)+" expects secrets that aren't set on project "+utils.Aqua(projectRef)+": "+strings.Join(missing, ", ")+". Set them with "+utils.Aqua("supabase secrets set")+".")
 		}
+	}
+}
 
-		req, err := http.NewRequest("GET", "https://api.supabase.io/v1/projects/"+projectRef+"/functions/"+slug, nil)
+// upload creates or updates the Function, unless the deployed Function already
+// has the same body and verify_jwt.
+func upload(client *api.Client, projectRef string, slug string, body string, verifyJWT bool) result {
+	function, err := client.GetFunction(ctx, projectRef, slug)
+	if api.IsNotFound(err) {
+		// Function doesn't exist yet, so do a POST
+		function, err := client.CreateFunction(ctx, projectRef, api.CreateFunctionRequest{
+			Slug:      slug,
+			Name:      slug,
+			Body:      body,
+			VerifyJwt: verifyJWT,
+		})
 		if err != nil {
-			return err
-		}
-		req.Header.Add("Authorization", "Bearer "+string(accessToken))
-		resp, err := http.DefaultClient.Do(req)
-		if err != nil {
-			return err
+			return failure(slug, fmt.Errorf("Failed to create a new Function on the Supabase project: %w", err))
 		}
-		defer resp.Body.Close()
+		return result{slug: slug, status: statusCreated, version: function.Version, id: function.Id}
+	} else if err != nil {
+		return failure(slug, fmt.Errorf("Unexpected error deploying Function: %w", err))
+	}
 
-		switch resp.StatusCode {
-		case http.StatusNotFound: // Function doesn't exist yet, so do a POST
-			jsonBytes, err := json.Marshal(map[string]interface{}{"slug": slug, "name": slug, "body": newFunctionBody, "verify_jwt": verifyJWT})
-			if err != nil {
-				return err
-			}
+	// A body that can't be fetched is redeployed.
+	if function.VerifyJwt == verifyJWT {
+		if deployed, err := client.GetFunctionBody(ctx, projectRef, slug); err == nil && bytes.Equal(deployed, []byte(body)) {
+			return result{slug: slug, status: statusSkipped, version: function.Version, id: function.Id}
+		}
+	}
 
-			req, err := http.NewRequest("POST", "https://api.supabase.io/v1/projects/"+projectRef+"/functions", bytes.NewReader(jsonBytes))
-			if err != nil {
-				return err
-			}
-			req.Header.Add("Authorization", "Bearer "+string(accessToken))
-			req.Header.Add("Content-Type", "application/json")
-			resp, err := http.DefaultClient.Do(req)
-			if err != nil {
-				return err
-			}
-			defer resp.Body.Close()
+	// Function already exists, so do a PATCH
+	function, err = client.UpdateFunction(ctx, projectRef, slug, api.UpdateFunctionRequest{
+		Body:      body,
+		VerifyJwt: verifyJWT,
+	})
+	if err != nil {
+		return failure(slug, fmt.Errorf("Failed to update an existing Function's body on the Supabase project: %w", err))
+	}
+	return result{slug: slug, status: statusUpdated, version: function.Version, id: function.Id}
+}
 
-			body, err := io.ReadAll(resp.Body)
-			if resp.StatusCode != http.StatusOK {
-				if err != nil {
-					return fmt.Errorf("Failed to create a new Function on the Supabase project: %w", err)
-				}
-				return errors.New("Failed to create a new Function on the Supabase project: " + string(body))
-			}
-			if err := json.Unmarshal(body, &data); err != nil {
-				return fmt.Errorf("Failed to create a new Function on the Supabase project: %w", err)
-			}
-		case http.StatusOK: // Function already exists, so do a PATCH
-			jsonBytes, err := json.Marshal(map[string]interface{}{"body": newFunctionBody, "verify_jwt": verifyJWT})
-			if err != nil {
-				return err
-			}
+func failure(slug string, err error) result {
+	return result{slug: slug, status: statusFailed, err: err}
+}
 
-			req, err := http.NewRequest("PATCH", "https://api.supabase.io/v1/projects/"+projectRef+"/functions/"+slug, bytes.NewReader(jsonBytes))
-			if err != nil {
-				return err
-			}
-			req.Header.Add("Authorization", "Bearer "+string(accessToken))
-			req.Header.Add("Content-Type", "application/json")
-			resp, err := http.DefaultClient.Do(req)
-			if err != nil {
-				return err
-			}
-			defer resp.Body.Close()
-			body, err := io.ReadAll(resp.Body)
-			if resp.StatusCode != http.StatusOK {
-				if err != nil {
-					return fmt.Errorf("Failed to update an existing Function's body on the Supabase project: %w", err)
-				}
-				return errors.New("Failed to update an existing Function's body on the Supabase project: " + string(body))
-			}
-			if err := json.Unmarshal(body, &data); err != nil {
-				return fmt.Errorf("Failed to update an existing Function's body on the Supabase project: %w", err)
-			}
-		default:
-			body, err := io.ReadAll(resp.Body)
-			if err != nil {
-				return fmt.Errorf("Unexpected error deploying Function: %w", err)
-			}
-			return errors.New("Unexpected error deploying Function: " + string(body))
+func printSummary(results []result) error {
+	table := `|NAME|STATUS|VERSION|ERROR|
+|-|-|-|-|
+`
+	for _, result := range results {
+		version := ""
+		if result.version > 0 {
+			version = fmt.Sprintf("`%d`", result.version)
 		}
+		// Only the first line of errors fits, the rest is printed above.
+		var message string
+		if result.err != nil {
+			message = strings.SplitN(result.err.Error(), "\n", 2)[0]
+		}
+		table += fmt.Sprintf(
+			"|`%s`|`%s`|%s|%s|\n",
+			strings.ReplaceAll(result.slug, "|", "\\|"),
+			result.status,
+			version,
+			strings.ReplaceAll(message, "|", "\\|"),
+		)
 	}
 
-	fmt.Println("Deployed Function " + utils.Aqua(slug) + " on project " + utils.Aqua(projectRef))
-
-	url := fmt.Sprintf("https://app.supabase.io/project/%v/functions/%v/details", projectRef, data.Id)
-	fmt.Println("You can inspect your deployment in the Dashboard: " + url)
-
+	r, err := glamour.NewTermRenderer(
+		glamour.WithAutoStyle(),
+		glamour.WithWordWrap(-1),
+	)
+	if err != nil {
+		return err
+	}
+	out, err := r.Render(table)
+	if err != nil {
+		return err
+	}
+	fmt.Print(out)
 	return nil
 }
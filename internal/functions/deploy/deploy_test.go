@@ -0,0 +1,57 @@
+package deploy
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/supabase/cli/internal/api"
+)
+
+func TestUpload(t *testing.T) {
+	deployed := map[string]string{"unchanged": "body", "changed": "old body"}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		prefix := "/v1/projects/abcdefghijklmnopqrst/functions"
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == prefix:
+			_ = json.NewEncoder(w).Encode(api.Function{Id: "1", Slug: "new", Version: 1})
+		case r.Method == http.MethodPatch && r.URL.Path == prefix+"/changed":
+			_ = json.NewEncoder(w).Encode(api.Function{Id: "2", Slug: "changed", Version: 3})
+		case r.Method == http.MethodPatch && r.URL.Path == prefix+"/broken":
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"message":"Invalid body"}`))
+		case r.Method == http.MethodGet && r.URL.Path == prefix+"/unchanged/body":
+			_, _ = w.Write([]byte(deployed["unchanged"]))
+		case r.Method == http.MethodGet && r.URL.Path == prefix+"/changed/body":
+			_, _ = w.Write([]byte(deployed["changed"]))
+		case r.Method == http.MethodGet && r.URL.Path == prefix+"/new":
+			w.WriteHeader(http.StatusNotFound)
+		case r.Method == http.MethodGet:
+			_ = json.NewEncoder(w).Encode(api.Function{Id: "3", Version: 2, VerifyJwt: true})
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+	client := api.NewClient(server.URL, "token")
+	client.MaxRetries = 0
+
+	for slug, expected := range map[string]string{
+		"new":       statusCreated,
+		"unchanged": statusSkipped,
+		"changed":   statusUpdated,
+		"broken":    statusFailed,
+	} {
+		result := upload(client, "abcdefghijklmnopqrst", slug, "body", true)
+		if result.status != expected {
+			t.Errorf("%s: expected %s, got %s (%v)", slug, expected, result.status, result.err)
+		}
+	}
+
+	// A changed verify_jwt is deployed even if the body is the same.
+	if result := upload(client, "abcdefghijklmnopqrst", "changed", "old body", false); result.status != statusUpdated {
+		t.Errorf("expected %s, got %s", statusUpdated, result.status)
+	}
+}
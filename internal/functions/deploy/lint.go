@@ -0,0 +1,219 @@
+package deploy
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Calls that are rejected in Function bundles, with the reason reported.
+var lintRules = map[string]string{
+	"eval":     "eval can be harmful.",
+	"Function": "The Function constructor is eval.",
+}
+
+// lint rejects bundles that call eval() or the Function constructor, like
+// eslint's no-eval and no-new-func rules. Strings, comments and regular
+// expressions are skipped, and property accesses like `obj.eval()` are allowed.
+func lint(body string) error {
+	s := lintScanner{src: body, line: 1}
+	for s.pos < len(s.src) {
+		ident, line := s.next()
+		if ident == "" {
+			continue
+		}
+		if message, ok := lintRules[ident]; ok && s.callFollows() {
+			return fmt.Errorf("Error linting function: line %d: %s\n%s", line, message, excerpt(body, line))
+		}
+	}
+	return nil
+}
+
+// excerpt returns the line, trimmed and shortened since bundles may have very
+// long lines.
+func excerpt(body string, line int) string {
+	lines := strings.Split(body, "\n")
+	text := strings.TrimSpace(lines[line-1])
+	if len(text) > 120 {
+		text = text[:117] + "..."
+	}
+	return "    " + text
+}
+
+// Keywords after which a slash starts a regular expression.
+var regexKeywords = map[string]bool{
+	"await": true, "case": true, "delete": true, "do": true, "else": true, "in": true, "instanceof": true,
+	"new": true, "of": true, "return": true, "throw": true, "typeof": true, "void": true, "yield": true,
+}
+
+// lintScanner is a minimal JavaScript tokenizer, good enough to find
+// identifiers outside of literals and comments.
+type lintScanner struct {
+	src  string
+	pos  int
+	line int
+	// The last significant character, to tell regular expressions from
+	// division and property accesses from plain identifiers.
+	prev byte
+	// Brace depths at which template literal substitutions end.
+	templates []int
+	braces    int
+}
+
+// next advances past one token, returning it and its line if it's an
+// identifier that isn't a property access.
+func (s *lintScanner) next() (string, int) {
+	c := s.src[s.pos]
+	switch {
+	case c == '\n':
+		s.line++
+		s.pos++
+	case c == ' ' || c == '\t' || c == '\r':
+		s.pos++
+	case strings.HasPrefix(s.src[s.pos:], "//"):
+		for s.pos < len(s.src) && s.src[s.pos] != '\n' {
+			s.pos++
+		}
+	case strings.HasPrefix(s.src[s.pos:], "/*"):
+		end := strings.Index(s.src[s.pos+2:], "*/")
+		if end < 0 {
+			s.pos = len(s.src)
+			break
+		}
+		s.line += strings.Count(s.src[s.pos:s.pos+2+end], "\n")
+		s.pos += end + 4
+	case c == '\'' || c == '"':
+		s.skipString(c)
+		s.prev = c
+	case c == '`':
+		s.pos++
+		s.skipTemplate()
+	case c == '/' && s.regexAllowed():
+		s.skipRegex()
+		s.prev = c
+	case c == '{':
+		s.braces++
+		s.pos++
+		s.prev = c
+	case c == '}' && len(s.templates) > 0 && s.templates[len(s.templates)-1] == s.braces:
+		// End of a template literal substitution.
+		s.templates = s.templates[:len(s.templates)-1]
+		s.pos++
+		s.skipTemplate()
+	case c == '}':
+		s.braces--
+		s.pos++
+		s.prev = c
+	case isIdentStart(c):
+		start := s.pos
+		for s.pos < len(s.src) && isIdentPart(s.src[s.pos]) {
+			s.pos++
+		}
+		ident := s.src[start:s.pos]
+		property := s.prev == '.'
+		s.prev = 'a'
+		if regexKeywords[ident] && !property {
+			s.prev = '('
+		}
+		if !property {
+			return ident, s.line
+		}
+	default:
+		s.pos++
+		s.prev = c
+	}
+	return "", 0
+}
+
+// callFollows reports whether the identifier just scanned is called, i.e.
+// followed by an opening parenthesis.
+func (s *lintScanner) callFollows() bool {
+	rest := strings.TrimLeft(s.src[s.pos:], " \t\r\n")
+	return strings.HasPrefix(rest, "(")
+}
+
+// regexAllowed reports whether a slash starts a regular expression rather than
+// a division, based on the preceding token.
+func (s *lintScanner) regexAllowed() bool {
+	return s.prev == 0 || strings.IndexByte("(,=:[!&|?{};+-*%<>~^", s.prev) >= 0
+}
+
+func (s *lintScanner) skipString(quote byte) {
+	for s.pos++; s.pos < len(s.src); s.pos++ {
+		switch s.src[s.pos] {
+		case '\\':
+			s.skipEscape()
+		case '\n':
+			// Unterminated string, resume on the next line.
+			return
+		case quote:
+			s.pos++
+			return
+		}
+	}
+}
+
+// skipEscape skips the character after a backslash, which may be a line
+// continuation.
+func (s *lintScanner) skipEscape() {
+	s.pos++
+	if s.pos < len(s.src) && s.src[s.pos] == '\n' {
+		s.line++
+	}
+}
+
+// skipTemplate skips a template literal up to its closing backtick, or up to
+// the start of a substitution, which is scanned as code.
+func (s *lintScanner) skipTemplate() {
+	for ; s.pos < len(s.src); s.pos++ {
+		switch s.src[s.pos] {
+		case '\\':
+			s.skipEscape()
+		case '\n':
+			s.line++
+		case '`':
+			s.pos++
+			s.prev = '`'
+			return
+		case '$':
+			if s.pos+1 < len(s.src) && s.src[s.pos+1] == '{' {
+				s.pos += 2
+				s.templates = append(s.templates, s.braces)
+				s.prev = '{'
+				return
+			}
+		}
+	}
+}
+
+func (s *lintScanner) skipRegex() {
+	inClass := false
+	for s.pos++; s.pos < len(s.src); s.pos++ {
+		switch s.src[s.pos] {
+		case '\\':
+			s.pos++
+		case '[':
+			inClass = true
+		case ']':
+			inClass = false
+		case '\n':
+			return
+		case '/':
+			if !inClass {
+				s.pos++
+				// Flags.
+				for s.pos < len(s.src) && isIdentPart(s.src[s.pos]) {
+					s.pos++
+				}
+				return
+			}
+		}
+	}
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || c == '$' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= 0x80
+}
+
+func isIdentPart(c byte) bool {
+	return isIdentStart(c) || c >= '0' && c <= '9'
+}
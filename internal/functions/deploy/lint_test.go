@@ -0,0 +1,48 @@
+package deploy
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		body string
+		line int
+	}{
+		{"eval call", "const a = 1;\nconst b = eval(\"a\");\n", 2},
+		{"eval with space", "eval (code)", 1},
+		{"Function constructor", "const f = new Function(\"return 1\");", 1},
+		{"Function call", "x;\n\nconst f = Function(\"return 1\");", 3},
+		{"template substitution", "const s = `a ${eval(x)} b`;", 1},
+		{"after multiline template", "const s = `a\n${b}\n`;\neval(s);", 4},
+		{"after multiline comment", "/* a\nb */\neval(s);", 3},
+		{"after regex with quote", "const r = /\"/g;\neval(s);", 2},
+		{"after returned regex", "function f() { return /'/.test(s) }\neval(s);", 2},
+		{"clean", "const a = 1;\nconsole.log(a);\n", 0},
+		{"property access", "obj.eval(code);\nwindow.Function(x);", 0},
+		{"in strings", "const a = 'eval(x)' + \"new Function()\";", 0},
+		{"in template", "const a = `eval(x) ${\"Function()\"}`;", 0},
+		{"in comments", "// eval(x)\n/* new Function() */", 0},
+		{"in regex", "const r = /eval(x)/;", 0},
+		{"not called", "const evaluate = eval_; typeof Function;", 0},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := lint(tc.body)
+			if tc.line == 0 {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if expected := fmt.Sprintf("line %d:", tc.line); !strings.Contains(err.Error(), expected) {
+				t.Errorf("expected %q in %q", expected, err.Error())
+			}
+		})
+	}
+}
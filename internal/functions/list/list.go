@@ -0,0 +1,103 @@
+package list
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/glamour"
+	"github.com/supabase/cli/internal/api"
+	"github.com/supabase/cli/internal/login"
+	"github.com/supabase/cli/internal/utils"
+)
+
+var ctx = context.Background()
+
+func Run(projectRefArg string) error {
+	// 1. Sanity checks.
+	{
+		if err := utils.AssertSupabaseCliIsSetUp(); err != nil {
+			return err
+		}
+		if _, err := utils.LoadAccessToken(); err != nil && strings.HasPrefix(err.Error(), "Access token not provided. Supply an access token by running") {
+			if err := login.Run(); err != nil {
+				return err
+			}
+		} else if err != nil {
+			return err
+		}
+		if len(projectRefArg) == 0 {
+			if err := utils.AssertIsLinked(); err != nil {
+				return err
+			}
+		} else {
+			matched, err := regexp.MatchString(`^[a-z]{20}$`, projectRefArg)
+			if err != nil {
+				return err
+			}
+			if !matched {
+				return errors.New("Invalid project ref format. Must be like `abcdefghijklmnopqrst`.")
+			}
+		}
+	}
+
+	// 2. List Functions.
+	{
+		// --project-ref overrides value on disk
+		projectRef := projectRefArg
+		if len(projectRef) == 0 {
+			projectRefBytes, err := os.ReadFile(".supabase/temp/project-ref")
+			if err != nil {
+				return err
+			}
+			projectRef = string(projectRefBytes)
+		}
+
+		client, err := api.NewDefaultClient()
+		if err != nil {
+			return err
+		}
+
+		functions, err := client.ListFunctions(ctx, projectRef)
+		if err != nil {
+			return fmt.Errorf("Unexpected error retrieving Functions: %w", err)
+		}
+
+		if utils.OutputFormat.Value != utils.OutputPretty {
+			return utils.EncodeOutput(utils.OutputFormat.Value, os.Stdout, functions)
+		}
+
+		table := `|NAME|STATUS|VERSION|VERIFY JWT|UPDATED AT (UTC)|
+|-|-|-|-|-|
+`
+		for _, function := range functions {
+			table += fmt.Sprintf(
+				"|`%s`|`%s`|`%d`|`%t`|`%s`|\n",
+				strings.ReplaceAll(function.Slug, "|", "\\|"),
+				function.Status,
+				function.Version,
+				function.VerifyJwt,
+				time.UnixMilli(function.UpdatedAt).UTC().Format("2006-01-02 15:04:05"),
+			)
+		}
+
+		r, err := glamour.NewTermRenderer(
+			glamour.WithAutoStyle(),
+			glamour.WithWordWrap(-1),
+		)
+		if err != nil {
+			return err
+		}
+		out, err := r.Render(table)
+		if err != nil {
+			return err
+		}
+		fmt.Print(out)
+	}
+
+	return nil
+}
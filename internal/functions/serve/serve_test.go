@@ -0,0 +1,79 @@
+package serve
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/BurntSushi/toml"
+	"github.com/supabase/cli/internal/utils"
+)
+
+func TestPrefixWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := &prefixWriter{w: &buf, prefix: "hello | "}
+	for _, chunk := range []string{"Listening", " on :8000\nDownload", "ing\n", "partial"} {
+		if _, err := w.Write([]byte(chunk)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expected := "hello | Listening on :8000\nhello | Downloading\n"
+	if buf.String() != expected {
+		t.Errorf("expected %q, got %q", expected, buf.String())
+	}
+}
+
+func TestFunctionEnv(t *testing.T) {
+	config := utils.Config
+	t.Cleanup(func() { utils.Config = config })
+	dir := t.TempDir()
+	utils.Config.Edgefunctions.SrcPath = dir
+	utils.Config.Edgefunctions.FunctionsPath = "functions"
+	if _, err := toml.Decode(`
+[functions.hello]
+env_file = ".env.local"
+secrets = ["API_KEY"]
+`, &utils.Config); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.MkdirAll(filepath.Join(dir, "functions", "hello"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "functions", "hello", ".env.local"), []byte("API_KEY=local\nOTHER=1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	envFilePath := filepath.Join(dir, ".env")
+	if err := os.WriteFile(envFilePath, []byte("API_KEY=flag\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var warnings bytes.Buffer
+	env, err := functionEnv("hello", envFilePath, &warnings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// --env-file overrides the Function's env file, and undeclared secrets are dropped.
+	lookup := func(prefix string) []string {
+		matches := []string{}
+		for _, e := range env {
+			if strings.HasPrefix(e, prefix) {
+				matches = append(matches, e)
+			}
+		}
+		return matches
+	}
+	if apiKey := lookup("API_KEY="); len(apiKey) != 1 || apiKey[0] != "API_KEY=flag" {
+		t.Errorf("unexpected API_KEY: %v", env)
+	}
+	if other := lookup("OTHER="); len(other) != 0 {
+		t.Errorf("expected OTHER to be dropped: %v", env)
+	}
+	if !strings.Contains(warnings.String(), "OTHER") {
+		t.Errorf("expected a warning about OTHER, got %q", warnings.String())
+	}
+}
@@ -0,0 +1,39 @@
+// Routes /<slug>/* to the relay container serving that Function. Run by
+// `supabase functions serve` in place of the relay that Kong forwards
+// /functions/v1/* to.
+const upstreams: Record<string, string> = JSON.parse(Deno.env.get("UPSTREAMS") ?? "{}")
+
+function jsonResponse(status: number, msg: string): Response {
+  return new Response(JSON.stringify({ msg }), {
+    status,
+    headers: { "Content-Type": "application/json" },
+  })
+}
+
+async function handle(req: Request): Promise<Response> {
+  const url = new URL(req.url)
+  const slug = url.pathname.split("/")[1]
+  const upstream = upstreams[slug]
+  if (!upstream) {
+    return jsonResponse(404, `Function ${slug} is not being served.`)
+  }
+
+  try {
+    return await fetch(upstream + url.pathname + url.search, {
+      method: req.method,
+      headers: req.headers,
+      body: req.body,
+      redirect: "manual",
+    })
+  } catch (e) {
+    return jsonResponse(503, `Function ${slug} is not ready: ${e}`)
+  }
+}
+
+for await (const conn of Deno.listen({ port: 8081 })) {
+  ;(async () => {
+    for await (const { request, respondWith } of Deno.serveHttp(conn)) {
+      respondWith(handle(request)).catch(() => {})
+    }
+  })().catch(() => {})
+}
@@ -0,0 +1,205 @@
+package types
+
+import (
+	"fmt"
+	"go/format"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+var goBaseTypes = map[string]string{
+	"bool":        "bool",
+	"int2":        "int16",
+	"int4":        "int32",
+	"int8":        "int64",
+	"float4":      "float32",
+	"float8":      "float64",
+	"numeric":     "float64",
+	"oid":         "uint32",
+	"json":        "json.RawMessage",
+	"jsonb":       "json.RawMessage",
+	"record":      "map[string]interface{}",
+	"text":        "string",
+	"varchar":     "string",
+	"bpchar":      "string",
+	"char":        "string",
+	"name":        "string",
+	"citext":      "string",
+	"uuid":        "string",
+	"date":        "string",
+	"time":        "string",
+	"timetz":      "string",
+	"timestamp":   "string",
+	"timestamptz": "string",
+	"interval":    "string",
+	"bytea":       "string",
+	"inet":        "string",
+	"cidr":        "string",
+	"macaddr":     "string",
+	"tsvector":    "string",
+	"tsquery":     "string",
+	"xml":         "string",
+}
+
+// generateGo renders the database as a `database` package, with a struct per
+// table and view for each of row, insert and update, named `<Schema><Table>Row`
+// etc. Functions get `<Schema><Function>Args` and `<Schema><Function>Returns`.
+func generateGo(db database) ([]byte, error) {
+	var body strings.Builder
+
+	for _, schema := range db.Schemas {
+		for _, enum := range db.enums(schema) {
+			name := goName(enum.Schema, enum.Name)
+			fmt.Fprintf(&body, "type %s string\n\nconst (\n", name)
+			for _, value := range enum.Values {
+				fmt.Fprintf(&body, "%s %s = %s\n", goName(name, value), name, strconv.Quote(value))
+			}
+			body.WriteString(")\n\n")
+		}
+
+		for _, t := range append(append([]table{}, db.Tables...), db.Views...) {
+			if t.Schema != schema {
+				continue
+			}
+			name := goName(t.Schema, t.Name)
+
+			fmt.Fprintf(&body, "type %sRow struct {\n", name)
+			for _, c := range t.Columns {
+				fmt.Fprintf(&body, "%s %s `json:%s`\n", goName(c.Name), goNullable(db.goType(c.Type), c.Nullable), strconv.Quote(c.Name))
+			}
+			body.WriteString("}\n\n")
+
+			if t.Insertable {
+				fmt.Fprintf(&body, "type %sInsert struct {\n", name)
+				for _, c := range t.Columns {
+					if c.Generated {
+						continue
+					}
+					if c.Nullable || c.HasDefault {
+						fmt.Fprintf(&body, "%s %s `json:%s`\n", goName(c.Name), goNullable(db.goType(c.Type), true), strconv.Quote(c.Name+",omitempty"))
+					} else {
+						fmt.Fprintf(&body, "%s %s `json:%s`\n", goName(c.Name), db.goType(c.Type), strconv.Quote(c.Name))
+					}
+				}
+				body.WriteString("}\n\n")
+			}
+
+			if t.Updatable {
+				fmt.Fprintf(&body, "type %sUpdate struct {\n", name)
+				for _, c := range t.Columns {
+					if !c.Generated {
+						fmt.Fprintf(&body, "%s %s `json:%s`\n", goName(c.Name), goNullable(db.goType(c.Type), true), strconv.Quote(c.Name+",omitempty"))
+					}
+				}
+				body.WriteString("}\n\n")
+			}
+		}
+
+		// Overloads are numbered in the order they were created.
+		seen := map[string]int{}
+		for _, f := range db.Functions {
+			if f.Schema != schema {
+				continue
+			}
+			name := goName(f.Schema, f.Name)
+			seen[name]++
+			if seen[name] > 1 {
+				name += strconv.Itoa(seen[name])
+			}
+
+			fmt.Fprintf(&body, "type %sArgs struct {\n", name)
+			for _, arg := range f.Args {
+				if arg.HasDefault {
+					fmt.Fprintf(&body, "%s %s `json:%s`\n", goName(arg.Name), goNullable(db.goType(arg.Type), true), strconv.Quote(arg.Name+",omitempty"))
+				} else {
+					fmt.Fprintf(&body, "%s %s `json:%s`\n", goName(arg.Name), db.goType(arg.Type), strconv.Quote(arg.Name))
+				}
+			}
+			body.WriteString("}\n\n")
+
+			returns := db.goType(f.ReturnType)
+			if len(f.ReturnColumns) > 0 {
+				returns = name + "ReturnsRow"
+				fmt.Fprintf(&body, "type %s struct {\n", returns)
+				for _, column := range f.ReturnColumns {
+					fmt.Fprintf(&body, "%s %s `json:%s`\n", goName(column.Name), goNullable(db.goType(column.Type), true), strconv.Quote(column.Name))
+				}
+				body.WriteString("}\n\n")
+			}
+			if returns == "" {
+				continue
+			}
+			if f.ReturnsSet {
+				returns = "[]" + returns
+			}
+			fmt.Fprintf(&body, "type %sReturns = %s\n\n", name, returns)
+		}
+	}
+
+	src := "// Code generated by supabase gen types. DO NOT EDIT.\n\npackage database\n\n"
+	if strings.Contains(body.String(), "json.RawMessage") {
+		src += "import \"encoding/json\"\n\n"
+	}
+	return format.Source([]byte(src + body.String()))
+}
+
+// goType returns the Go type of the given Postgres type, or "" for void.
+func (db database) goType(oid uint32) string {
+	t, ok := db.Types[oid]
+	if !ok {
+		return "interface{}"
+	}
+
+	if t.Category == "A" && t.Elem != 0 {
+		return "[]" + db.goType(t.Elem)
+	}
+	switch t.Kind {
+	case "d":
+		return db.goType(t.BaseType)
+	case "e":
+		if db.hasSchema(t.Schema) {
+			return goName(t.Schema, t.Name)
+		}
+		return "string"
+	case "c":
+		if db.relationKind(t.Schema, t.Name) != "" && db.hasSchema(t.Schema) {
+			return goName(t.Schema, t.Name) + "Row"
+		}
+		return "map[string]interface{}"
+	}
+	if t.Name == "void" && t.Schema == "pg_catalog" {
+		return ""
+	}
+	if goType, ok := goBaseTypes[t.Name]; ok && (t.Schema == "pg_catalog" || t.Name == "citext") {
+		return goType
+	}
+	return "interface{}"
+}
+
+// goNullable makes a type nullable, unless it already is.
+func goNullable(goType string, nullable bool) string {
+	if !nullable || strings.HasPrefix(goType, "[]") || strings.HasPrefix(goType, "map[") || goType == "interface{}" || goType == "json.RawMessage" {
+		return goType
+	}
+	return "*" + goType
+}
+
+// goName joins the given names into an exported identifier, e.g. `public` and
+// `user_profiles` into `PublicUserProfiles`.
+func goName(names ...string) string {
+	var name strings.Builder
+	for _, n := range names {
+		for _, word := range strings.FieldsFunc(n, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		}) {
+			runes := []rune(word)
+			runes[0] = unicode.ToUpper(runes[0])
+			name.WriteString(string(runes))
+		}
+	}
+	if name.Len() == 0 || !unicode.IsLetter([]rune(name.String())[0]) {
+		return "X" + name.String()
+	}
+	return name.String()
+}
@@ -0,0 +1,281 @@
+package types
+
+import (
+	"context"
+	"sort"
+
+	pgx "github.com/jackc/pgx/v4"
+)
+
+type (
+	pgType struct {
+		Schema string
+		Name   string
+		// pg_type.typtype: b (base), c (composite), d (domain), e (enum), p (pseudo), r/m (range).
+		Kind     string
+		Category string
+		// Element type of arrays.
+		Elem uint32
+		// Underlying type of domains.
+		BaseType uint32
+		// Labels of enums, in order.
+		Values []string
+	}
+
+	column struct {
+		Name     string
+		Type     uint32
+		Nullable bool
+		// Can be omitted on insert, because it has a default or is an identity column.
+		HasDefault bool
+		// Can't be written at all: generated columns and GENERATED ALWAYS identity columns.
+		Generated bool
+	}
+
+	table struct {
+		Schema  string
+		Name    string
+		Columns []column
+		// Always true for tables. Views can be written to only if they're automatically updatable.
+		Insertable bool
+		Updatable  bool
+	}
+
+	argument struct {
+		Name       string
+		Type       uint32
+		HasDefault bool
+	}
+
+	function struct {
+		Schema     string
+		Name       string
+		Args       []argument
+		ReturnType uint32
+		ReturnsSet bool
+		// OUT and TABLE parameters, if the function returns a record of them.
+		ReturnColumns []argument
+	}
+
+	database struct {
+		Schemas   []string
+		Types     map[uint32]pgType
+		Tables    []table
+		Views     []table
+		Functions []function
+	}
+)
+
+const recordOid = 2249
+
+const listTypes = `SELECT t.oid::int8, n.nspname, t.typname, t.typtype::text, t.typcategory::text, t.typelem::int8, t.typbasetype::int8,
+  COALESCE((SELECT array_agg(e.enumlabel::text ORDER BY e.enumsortorder) FROM pg_enum e WHERE e.enumtypid = t.oid), '{}')
+FROM pg_type t
+JOIN pg_namespace n ON n.oid = t.typnamespace`
+
+const listRelations = `SELECT n.nspname, c.relname, c.relkind IN ('v', 'm'), pg_relation_is_updatable(c.oid::regclass, false)
+FROM pg_class c
+JOIN pg_namespace n ON n.oid = c.relnamespace
+WHERE n.nspname = ANY($1) AND c.relkind IN ('r', 'p', 'f', 'v', 'm')
+ORDER BY n.nspname, c.relname`
+
+const listColumns = `SELECT n.nspname, c.relname, a.attname, a.atttypid::int8, NOT a.attnotnull, a.atthasdef OR a.attidentity <> '', a.attidentity = 'a' OR a.attgenerated <> ''
+FROM pg_attribute a
+JOIN pg_class c ON c.oid = a.attrelid
+JOIN pg_namespace n ON n.oid = c.relnamespace
+WHERE n.nspname = ANY($1) AND c.relkind IN ('r', 'p', 'f', 'v', 'm') AND a.attnum > 0 AND NOT a.attisdropped
+ORDER BY n.nspname, c.relname, a.attnum`
+
+// Functions installed by extensions and trigger functions can't be called
+// through the API, so they're left out.
+const listFunctions = `SELECT n.nspname, p.proname, COALESCE(p.proargnames, '{}'), COALESCE(p.proargmodes::text[], '{}'),
+  COALESCE(p.proallargtypes, p.proargtypes::oid[])::int8[], p.pronargdefaults, p.prorettype::int8, p.proretset
+FROM pg_proc p
+JOIN pg_namespace n ON n.oid = p.pronamespace
+WHERE n.nspname = ANY($1) AND p.prokind = 'f'
+  AND p.prorettype NOT IN ('trigger'::regtype, 'event_trigger'::regtype)
+  AND NOT EXISTS (SELECT 1 FROM pg_depend d WHERE d.classid = 'pg_proc'::regclass AND d.objid = p.oid AND d.deptype = 'e')
+ORDER BY n.nspname, p.proname, p.oid`
+
+func introspect(ctx context.Context, conn *pgx.Conn, schemas []string) (database, error) {
+	db := database{Schemas: schemas, Types: map[uint32]pgType{}}
+
+	// 1. Types, in all schemas so references to e.g. extension types resolve.
+	{
+		rows, err := conn.Query(ctx, listTypes)
+		if err != nil {
+			return db, err
+		}
+		for rows.Next() {
+			var oid, elem, baseType int64
+			var t pgType
+			if err := rows.Scan(&oid, &t.Schema, &t.Name, &t.Kind, &t.Category, &elem, &baseType, &t.Values); err != nil {
+				return db, err
+			}
+			t.Elem = uint32(elem)
+			t.BaseType = uint32(baseType)
+			db.Types[uint32(oid)] = t
+		}
+		if err := rows.Err(); err != nil {
+			return db, err
+		}
+	}
+
+	// 2. Tables and views.
+	{
+		rows, err := conn.Query(ctx, listRelations, schemas)
+		if err != nil {
+			return db, err
+		}
+		for rows.Next() {
+			var t table
+			var isView bool
+			var updatable int32
+			if err := rows.Scan(&t.Schema, &t.Name, &isView, &updatable); err != nil {
+				return db, err
+			}
+			if isView {
+				// https://www.postgresql.org/docs/current/functions-info.html
+				t.Insertable = updatable&8 != 0
+				t.Updatable = updatable&4 != 0
+				db.Views = append(db.Views, t)
+			} else {
+				t.Insertable = true
+				t.Updatable = true
+				db.Tables = append(db.Tables, t)
+			}
+		}
+		if err := rows.Err(); err != nil {
+			return db, err
+		}
+	}
+
+	// 3. Columns.
+	{
+		rows, err := conn.Query(ctx, listColumns, schemas)
+		if err != nil {
+			return db, err
+		}
+		for rows.Next() {
+			var schema, name string
+			var typ int64
+			var c column
+			if err := rows.Scan(&schema, &name, &c.Name, &typ, &c.Nullable, &c.HasDefault, &c.Generated); err != nil {
+				return db, err
+			}
+			c.Type = uint32(typ)
+			if t := db.findRelation(schema, name); t != nil {
+				t.Columns = append(t.Columns, c)
+			}
+		}
+		if err := rows.Err(); err != nil {
+			return db, err
+		}
+	}
+
+	// 4. Functions.
+	{
+		rows, err := conn.Query(ctx, listFunctions, schemas)
+		if err != nil {
+			return db, err
+		}
+		for rows.Next() {
+			var f function
+			var names, modes []string
+			var types []int64
+			var numDefaults int32
+			var returnType int64
+			if err := rows.Scan(&f.Schema, &f.Name, &names, &modes, &types, &numDefaults, &returnType, &f.ReturnsSet); err != nil {
+				return db, err
+			}
+			f.ReturnType = uint32(returnType)
+			if fn, ok := newFunction(f, names, modes, types, int(numDefaults)); ok {
+				db.Functions = append(db.Functions, fn)
+			}
+		}
+		if err := rows.Err(); err != nil {
+			return db, err
+		}
+	}
+
+	return db, nil
+}
+
+// newFunction sorts the parameters of a pg_proc row into arguments and return
+// columns. Functions with unnamed arguments are skipped, since the API can only
+// call functions by named arguments.
+func newFunction(f function, names, modes []string, types []int64, numDefaults int) (function, bool) {
+	for i, typ := range types {
+		mode := "i"
+		if i < len(modes) {
+			mode = modes[i]
+		}
+		name := ""
+		if i < len(names) {
+			name = names[i]
+		}
+
+		arg := argument{Name: name, Type: uint32(typ)}
+		switch mode {
+		case "i", "v":
+			f.Args = append(f.Args, arg)
+		case "b":
+			f.Args = append(f.Args, arg)
+			f.ReturnColumns = append(f.ReturnColumns, arg)
+		case "o", "t":
+			f.ReturnColumns = append(f.ReturnColumns, arg)
+		}
+	}
+
+	// Defaults apply to the trailing input arguments.
+	for i := len(f.Args) - numDefaults; i < len(f.Args); i++ {
+		if i >= 0 {
+			f.Args[i].HasDefault = true
+		}
+	}
+	for _, arg := range f.Args {
+		if arg.Name == "" {
+			return f, false
+		}
+	}
+	// A single OUT parameter is returned as a scalar of its type.
+	if f.ReturnType != recordOid {
+		f.ReturnColumns = nil
+	}
+
+	return f, true
+}
+
+func (db *database) findRelation(schema, name string) *table {
+	for _, relations := range [][]table{db.Tables, db.Views} {
+		for i := range relations {
+			if relations[i].Schema == schema && relations[i].Name == name {
+				return &relations[i]
+			}
+		}
+	}
+	return nil
+}
+
+func (db *database) hasSchema(schema string) bool {
+	for _, s := range db.Schemas {
+		if s == schema {
+			return true
+		}
+	}
+	return false
+}
+
+// enums returns the enums in the given schema, sorted by name.
+func (db *database) enums(schema string) []pgType {
+	enums := []pgType{}
+	for _, t := range db.Types {
+		if t.Kind == "e" && t.Schema == schema {
+			enums = append(enums, t)
+		}
+	}
+	sort.Slice(enums, func(i, j int) bool {
+		return enums[i].Name < enums[j].Name
+	})
+	return enums
+}
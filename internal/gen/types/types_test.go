@@ -0,0 +1,165 @@
+package types
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	pgx "github.com/jackc/pgx/v4"
+)
+
+func fixture() database {
+	f, _ := newFunction(function{Schema: "public", Name: "search_posts", ReturnType: recordOid, ReturnsSet: true},
+		[]string{"query", "max", "id", "title"}, []string{"i", "i", "t", "t"}, []int64{25, 23, 20, 25}, 1)
+
+	return database{
+		Schemas: []string{"public"},
+		Types: map[uint32]pgType{
+			16:    {Schema: "pg_catalog", Name: "bool", Kind: "b"},
+			20:    {Schema: "pg_catalog", Name: "int8", Kind: "b"},
+			23:    {Schema: "pg_catalog", Name: "int4", Kind: "b"},
+			25:    {Schema: "pg_catalog", Name: "text", Kind: "b"},
+			1009:  {Schema: "pg_catalog", Name: "_text", Kind: "b", Category: "A", Elem: 25},
+			2278:  {Schema: "pg_catalog", Name: "void", Kind: "p"},
+			3802:  {Schema: "pg_catalog", Name: "jsonb", Kind: "b"},
+			16384: {Schema: "public", Name: "status", Kind: "e", Values: []string{"draft", "published"}},
+			16390: {Schema: "auth", Name: "factor_type", Kind: "e", Values: []string{"totp"}},
+		},
+		Tables: []table{{
+			Schema: "public", Name: "posts", Insertable: true, Updatable: true,
+			Columns: []column{
+				{Name: "id", Type: 20, HasDefault: true, Generated: true},
+				{Name: "title", Type: 25},
+				{Name: "tags", Type: 1009, Nullable: true},
+				{Name: "status", Type: 16384, HasDefault: true},
+				{Name: "meta", Type: 3802, Nullable: true},
+				{Name: "2fa", Type: 16390, Nullable: true},
+			},
+		}},
+		Views: []table{{
+			Schema: "public", Name: "published_posts",
+			Columns: []column{{Name: "title", Type: 25, Nullable: true}},
+		}},
+		Functions: []function{f, {Schema: "public", Name: "ping", ReturnType: 2278}},
+	}
+}
+
+func assertContains(t *testing.T, out string, fragments ...string) {
+	for _, fragment := range fragments {
+		if !strings.Contains(out, fragment) {
+			t.Errorf("missing %q in:\n%s", fragment, out)
+		}
+	}
+}
+
+func TestGenerateTypescript(t *testing.T) {
+	out := generateTypescript(fixture())
+	assertContains(t, out, `export interface Database {
+  public: {
+    Tables: {
+      posts: {
+        Row: {
+          id: number
+          title: string
+          tags: string[] | null
+          status: Database["public"]["Enums"]["status"]
+          meta: Json | null
+          "2fa": "totp" | null
+        }
+        Insert: {
+          id?: never
+          title: string
+          tags?: string[] | null
+          status?: Database["public"]["Enums"]["status"]
+          meta?: Json | null
+          "2fa"?: "totp" | null
+        }
+        Update: {
+          id?: never
+          title?: string
+`, `    Views: {
+      published_posts: {
+        Row: {
+          title: string | null
+        }
+      }
+    }
+    Functions: {
+      search_posts: {
+        Args: {
+          query: string
+          max?: number
+        }
+        Returns: ({
+          id: number
+          title: string
+        })[]
+      }
+      ping: {
+        Args: Record<PropertyKey, never>
+        Returns: undefined
+      }
+    }
+    Enums: {
+      status: "draft" | "published"
+    }
+`)
+}
+
+func TestGenerateGo(t *testing.T) {
+	out, err := generateGo(fixture())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertContains(t, string(out),
+		"package database\n\nimport \"encoding/json\"\n",
+		"PublicStatusDraft     PublicStatus = \"draft\"",
+		"type PublicPostsRow struct {\n\tId     int64           `json:\"id\"`\n\tTitle  string          `json:\"title\"`\n\tTags   []string        `json:\"tags\"`\n\tStatus PublicStatus    `json:\"status\"`\n\tMeta   json.RawMessage `json:\"meta\"`\n\tX2fa   *string         `json:\"2fa\"`\n}",
+		"type PublicPostsInsert struct {\n\tTitle  string ",
+		"Status *PublicStatus   `json:\"status,omitempty\"`",
+		"type PublicSearchPostsArgs struct {\n\tQuery string `json:\"query\"`\n\tMax   *int32 `json:\"max,omitempty\"`\n}",
+		"type PublicSearchPostsReturns = []PublicSearchPostsReturnsRow",
+		"type PublicPingArgs struct {\n}",
+	)
+	if strings.Contains(string(out), "PublishedPostsInsert") || strings.Contains(string(out), "PublicPingReturns") {
+		t.Errorf("unexpected types in:\n%s", out)
+	}
+}
+
+// Runs against the Postgres at SUPABASE_TEST_DB_URL, e.g. the local database
+// started by `supabase start`. Skipped if unset.
+func TestIntrospect(t *testing.T) {
+	url := os.Getenv("SUPABASE_TEST_DB_URL")
+	if url == "" {
+		t.Skip("SUPABASE_TEST_DB_URL is unset")
+	}
+
+	ctx := context.Background()
+	conn, err := pgx.Connect(ctx, url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close(ctx)
+
+	if _, err := conn.Exec(ctx, `CREATE SCHEMA gen_types_test;
+CREATE TYPE gen_types_test.status AS ENUM ('draft', 'published');
+CREATE TABLE gen_types_test.posts (id bigint GENERATED ALWAYS AS IDENTITY, title text NOT NULL, status gen_types_test.status NOT NULL DEFAULT 'draft');
+CREATE VIEW gen_types_test.titles AS SELECT title FROM gen_types_test.posts;
+CREATE FUNCTION gen_types_test.count_posts(status gen_types_test.status DEFAULT 'draft') RETURNS bigint LANGUAGE sql AS $$ SELECT count(*) FROM gen_types_test.posts $$;`); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _, _ = conn.Exec(ctx, "DROP SCHEMA gen_types_test CASCADE") }()
+
+	db, err := introspect(ctx, conn, []string{"gen_types_test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertContains(t, generateTypescript(db),
+		"          id: number\n          title: string\n          status: Database[\"gen_types_test\"][\"Enums\"][\"status\"]\n",
+		"          id?: never\n          title: string\n          status?: Database[\"gen_types_test\"][\"Enums\"][\"status\"]\n",
+		"      titles: {\n        Row: {\n          title: string | null\n        }\n        Insert: {\n          title?: string | null\n",
+		"        Args: {\n          status?: Database[\"gen_types_test\"][\"Enums\"][\"status\"]\n        }\n        Returns: number\n",
+		"      status: \"draft\" | \"published\"\n",
+	)
+}
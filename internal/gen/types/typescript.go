@@ -0,0 +1,293 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var tsIdentifierPattern = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+var tsBaseTypes = map[string]string{
+	"bool":        "boolean",
+	"int2":        "number",
+	"int4":        "number",
+	"int8":        "number",
+	"float4":      "number",
+	"float8":      "number",
+	"numeric":     "number",
+	"oid":         "number",
+	"json":        "Json",
+	"jsonb":       "Json",
+	"void":        "undefined",
+	"record":      "Record<string, unknown>",
+	"text":        "string",
+	"varchar":     "string",
+	"bpchar":      "string",
+	"char":        "string",
+	"name":        "string",
+	"citext":      "string",
+	"uuid":        "string",
+	"date":        "string",
+	"time":        "string",
+	"timetz":      "string",
+	"timestamp":   "string",
+	"timestamptz": "string",
+	"interval":    "string",
+	"bytea":       "string",
+	"inet":        "string",
+	"cidr":        "string",
+	"macaddr":     "string",
+	"tsvector":    "string",
+	"tsquery":     "string",
+	"xml":         "string",
+}
+
+// tsWriter writes indented lines.
+type tsWriter struct {
+	strings.Builder
+	depth int
+}
+
+func (w *tsWriter) line(format string, args ...interface{}) {
+	w.WriteString(strings.Repeat("  ", w.depth))
+	fmt.Fprintf(w, format, args...)
+	w.WriteString("\n")
+}
+
+func (w *tsWriter) open(key string) {
+	w.line("%s: {", tsKey(key))
+	w.depth++
+}
+
+func (w *tsWriter) close() {
+	w.depth--
+	w.line("}")
+}
+
+// generateTypescript renders the database as a `Database` interface, in the
+// shape supabase-js expects.
+func generateTypescript(db database) string {
+	w := tsWriter{}
+	w.line(`export type Json =
+  | string
+  | number
+  | boolean
+  | null
+  | { [key: string]: Json }
+  | Json[]`)
+	w.line("")
+	w.line("export interface Database {")
+	w.depth++
+	for _, schema := range db.Schemas {
+		w.open(schema)
+
+		w.open("Tables")
+		writeTsRelations(&w, db, schema, db.Tables)
+		w.close()
+
+		w.open("Views")
+		writeTsRelations(&w, db, schema, db.Views)
+		w.close()
+
+		w.open("Functions")
+		functions := map[string][]function{}
+		names := []string{}
+		for _, f := range db.Functions {
+			if f.Schema != schema {
+				continue
+			}
+			if _, ok := functions[f.Name]; !ok {
+				names = append(names, f.Name)
+			}
+			functions[f.Name] = append(functions[f.Name], f)
+		}
+		for _, name := range names {
+			overloads := []string{}
+			for _, f := range functions[name] {
+				overloads = append(overloads, tsFunction(db, f, w.depth))
+			}
+			w.line("%s: %s", tsKey(name), strings.Join(overloads, " | "))
+		}
+		if len(names) == 0 {
+			w.line("[_ in never]: never")
+		}
+		w.close()
+
+		w.open("Enums")
+		enums := db.enums(schema)
+		for _, enum := range enums {
+			w.line("%s: %s", tsKey(enum.Name), tsEnum(enum))
+		}
+		if len(enums) == 0 {
+			w.line("[_ in never]: never")
+		}
+		w.close()
+
+		w.close()
+	}
+	w.depth--
+	w.line("}")
+	return w.String()
+}
+
+func writeTsRelations(w *tsWriter, db database, schema string, relations []table) {
+	empty := true
+	for _, t := range relations {
+		if t.Schema != schema {
+			continue
+		}
+		empty = false
+
+		w.open(t.Name)
+		w.open("Row")
+		for _, c := range t.Columns {
+			w.line("%s: %s", tsKey(c.Name), tsNullable(db.tsType(c.Type), c.Nullable))
+		}
+		w.close()
+		if t.Insertable {
+			w.open("Insert")
+			for _, c := range t.Columns {
+				if c.Generated {
+					w.line("%s?: never", tsKey(c.Name))
+				} else if c.Nullable || c.HasDefault {
+					w.line("%s?: %s", tsKey(c.Name), tsNullable(db.tsType(c.Type), c.Nullable))
+				} else {
+					w.line("%s: %s", tsKey(c.Name), db.tsType(c.Type))
+				}
+			}
+			w.close()
+		}
+		if t.Updatable {
+			w.open("Update")
+			for _, c := range t.Columns {
+				if c.Generated {
+					w.line("%s?: never", tsKey(c.Name))
+				} else {
+					w.line("%s?: %s", tsKey(c.Name), tsNullable(db.tsType(c.Type), c.Nullable))
+				}
+			}
+			w.close()
+		}
+		w.close()
+	}
+	if empty {
+		w.line("[_ in never]: never")
+	}
+}
+
+// tsFunction renders the signature of a function as an object literal
+// indented to `depth`.
+func tsFunction(db database, f function, depth int) string {
+	indent := strings.Repeat("  ", depth)
+	args := "Record<PropertyKey, never>"
+	if len(f.Args) > 0 {
+		props := []string{}
+		for _, arg := range f.Args {
+			optional := ""
+			if arg.HasDefault {
+				optional = "?"
+			}
+			props = append(props, indent+"    "+tsKey(arg.Name)+optional+": "+db.tsType(arg.Type))
+		}
+		args = "{\n" + strings.Join(props, "\n") + "\n" + indent + "  }"
+	}
+
+	returns := db.tsType(f.ReturnType)
+	if len(f.ReturnColumns) > 0 {
+		props := []string{}
+		for _, column := range f.ReturnColumns {
+			props = append(props, indent+"    "+tsKey(column.Name)+": "+db.tsType(column.Type))
+		}
+		returns = "{\n" + strings.Join(props, "\n") + "\n" + indent + "  }"
+	}
+	if f.ReturnsSet {
+		returns = tsArray(returns)
+	}
+
+	return "{\n" + indent + "  Args: " + args + "\n" + indent + "  Returns: " + returns + "\n" + indent + "}"
+}
+
+func (db database) tsType(oid uint32) string {
+	t, ok := db.Types[oid]
+	if !ok {
+		return "unknown"
+	}
+
+	if t.Category == "A" && t.Elem != 0 {
+		return tsArray(db.tsType(t.Elem))
+	}
+	switch t.Kind {
+	case "d":
+		return db.tsType(t.BaseType)
+	case "e":
+		if db.hasSchema(t.Schema) {
+			return fmt.Sprintf("Database[%s][\"Enums\"][%s]", tsString(t.Schema), tsString(t.Name))
+		}
+		return tsEnum(t)
+	case "c":
+		if kind := db.relationKind(t.Schema, t.Name); kind != "" && db.hasSchema(t.Schema) {
+			return fmt.Sprintf("Database[%s][%s][%s][\"Row\"]", tsString(t.Schema), tsString(kind), tsString(t.Name))
+		}
+		return "unknown"
+	}
+	if ts, ok := tsBaseTypes[t.Name]; ok && (t.Schema == "pg_catalog" || t.Name == "citext") {
+		return ts
+	}
+	return "unknown"
+}
+
+// relationKind returns the key of the table or view with the given name in
+// the `Database` interface, or "" if there is none.
+func (db database) relationKind(schema, name string) string {
+	for _, t := range db.Tables {
+		if t.Schema == schema && t.Name == name {
+			return "Tables"
+		}
+	}
+	for _, t := range db.Views {
+		if t.Schema == schema && t.Name == name {
+			return "Views"
+		}
+	}
+	return ""
+}
+
+func tsEnum(t pgType) string {
+	values := []string{}
+	for _, value := range t.Values {
+		values = append(values, tsString(value))
+	}
+	if len(values) == 0 {
+		return "never"
+	}
+	return strings.Join(values, " | ")
+}
+
+func tsArray(ts string) string {
+	if strings.ContainsAny(ts, " |") {
+		return "(" + ts + ")[]"
+	}
+	return ts + "[]"
+}
+
+func tsNullable(ts string, nullable bool) string {
+	if nullable {
+		return ts + " | null"
+	}
+	return ts
+}
+
+func tsKey(key string) string {
+	if tsIdentifierPattern.MatchString(key) {
+		return key
+	}
+	return tsString(key)
+}
+
+func tsString(s string) string {
+	// JSON strings are valid TypeScript strings.
+	b, _ := json.Marshal(s)
+	return string(b)
+}
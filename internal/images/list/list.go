@@ -0,0 +1,66 @@
+package list
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/charmbracelet/glamour"
+	"github.com/supabase/cli/internal/utils"
+)
+
+type imageEntry struct {
+	Image  string `json:"image"`
+	Pulled bool   `json:"pulled"`
+}
+
+// Run lists the images used by the local development setup, and whether
+// they've been pulled already.
+func Run() error {
+	// Sanity checks.
+	{
+		if err := utils.AssertDockerIsRunning(); err != nil {
+			return err
+		}
+		if err := utils.LoadConfig(); err != nil {
+			return err
+		}
+	}
+
+	entries := []imageEntry{}
+	for _, image := range utils.GetServiceImages() {
+		url := utils.GetRegistryImageUrl(image)
+		_, _, err := utils.Docker.ImageInspectWithRaw(context.Background(), url)
+		entries = append(entries, imageEntry{Image: url, Pulled: err == nil})
+	}
+
+	if utils.OutputFormat.Value != utils.OutputPretty {
+		return utils.EncodeOutput(utils.OutputFormat.Value, os.Stdout, entries)
+	}
+
+	table := `|IMAGE|PULLED|
+|-|-|
+`
+	for _, entry := range entries {
+		pulled := " "
+		if entry.Pulled {
+			pulled = "yes"
+		}
+		table += "|`" + entry.Image + "`|" + pulled + "|\n"
+	}
+
+	r, err := glamour.NewTermRenderer(
+		glamour.WithAutoStyle(),
+		glamour.WithWordWrap(-1),
+	)
+	if err != nil {
+		return err
+	}
+	out, err := r.Render(table)
+	if err != nil {
+		return err
+	}
+	fmt.Print(out)
+
+	return nil
+}
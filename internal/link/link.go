@@ -1,13 +1,13 @@
 package link
 
 import (
+	"context"
 	"errors"
 	"fmt"
-	"io"
-	"net/http"
 	"os"
 	"regexp"
 
+	"github.com/supabase/cli/internal/api"
 	"github.com/supabase/cli/internal/utils"
 )
 
@@ -22,29 +22,13 @@ func Run(projectRef string) error {
 			return errors.New("Invalid project ref format. Must be like `abcdefghijklmnopqrst`.")
 		}
 
-		accessToken, err := utils.LoadAccessToken()
+		client, err := api.NewDefaultClient()
 		if err != nil {
 			return err
 		}
 
-		req, err := http.NewRequest("GET", "https://api.supabase.io/v1/projects/"+projectRef+"/functions", nil)
-		if err != nil {
-			return err
-		}
-		req.Header.Add("Authorization", "Bearer "+string(accessToken))
-		resp, err := http.DefaultClient.Do(req)
-		if err != nil {
-			return err
-		}
-		defer resp.Body.Close()
-
-		if resp.StatusCode != 200 {
-			body, err := io.ReadAll(resp.Body)
-			if err != nil {
-				return fmt.Errorf("Authorization failed for the access token and project ref pair: %w", err)
-			}
-
-			return errors.New("Authorization failed for the access token and project ref pair: " + string(body))
+		if _, err := client.ListFunctions(context.Background(), projectRef); err != nil {
+			return fmt.Errorf("Authorization failed for the access token and project ref pair: %w", err)
 		}
 	}
 
@@ -0,0 +1,74 @@
+package baseline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	pgx "github.com/jackc/pgx/v4"
+	"github.com/supabase/cli/internal/utils"
+)
+
+var ctx = context.Background()
+
+// Run resets the remote migration history so that every local migration up to
+// and including version is marked as applied, and nothing else is.
+func Run(version string) error {
+	localVersions, err := utils.GetLocalMigrationVersions()
+	if err != nil {
+		return err
+	}
+
+	var baseline []string
+	{
+		found := false
+		for _, localVersion := range localVersions {
+			baseline = append(baseline, localVersion)
+			if localVersion == version {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return errors.New("Cannot find migration " + utils.Aqua(version) + " in " + utils.Bold(".supabase/migrations") + ".")
+		}
+	}
+
+	url, err := utils.LoadRemoteDbUrl()
+	if err != nil {
+		return err
+	}
+
+	conn, err := pgx.Connect(ctx, url)
+	if err != nil {
+		return err
+	}
+	defer conn.Close(context.Background())
+
+	if err := func() error {
+		tx, err := conn.Begin(ctx)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback(context.Background()) //nolint:errcheck
+
+		if _, err := tx.Exec(ctx, utils.CreateMigrationTableSql); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, "DELETE FROM supabase_migrations.schema_migrations"); err != nil {
+			return err
+		}
+		for _, v := range baseline {
+			if _, err := tx.Exec(ctx, "INSERT INTO supabase_migrations.schema_migrations(version) VALUES($1)", v); err != nil {
+				return fmt.Errorf("%w; while inserting migration %s", err, v)
+			}
+		}
+
+		return tx.Commit(ctx)
+	}(); err != nil {
+		return fmt.Errorf("Error setting migration baseline: %w", err)
+	}
+
+	fmt.Println("Finished " + utils.Aqua("supabase migration baseline") + ". Marked " + fmt.Sprint(len(baseline)) + " migration(s) as applied.")
+	return nil
+}
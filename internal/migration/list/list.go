@@ -0,0 +1,94 @@
+package list
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/charmbracelet/glamour"
+	pgx "github.com/jackc/pgx/v4"
+	"github.com/supabase/cli/internal/utils"
+)
+
+var ctx = context.Background()
+
+func Run() error {
+	url, err := utils.LoadRemoteDbUrl()
+	if err != nil {
+		return err
+	}
+
+	localVersions, err := utils.GetLocalMigrationVersions()
+	if err != nil {
+		return err
+	}
+
+	conn, err := pgx.Connect(ctx, url)
+	if err != nil {
+		return err
+	}
+	defer conn.Close(context.Background())
+
+	remoteVersions, err := utils.GetMigrationHistory(ctx, conn)
+	if err != nil {
+		return fmt.Errorf(`Error querying remote database: %w.
+Try running `+utils.Aqua("supabase db remote set")+".", err)
+	}
+
+	table := `|LOCAL|REMOTE|
+|-|-|
+`
+	for _, row := range zipVersions(localVersions, remoteVersions) {
+		table += "|" + formatCell(row[0]) + "|" + formatCell(row[1]) + "|\n"
+	}
+
+	r, err := glamour.NewTermRenderer(
+		glamour.WithAutoStyle(),
+		glamour.WithWordWrap(-1),
+	)
+	if err != nil {
+		return err
+	}
+	out, err := r.Render(table)
+	if err != nil {
+		return err
+	}
+	fmt.Print(out)
+
+	return nil
+}
+
+// zipVersions lines up local and remote versions by value, leaving a blank
+// on the side where a version is missing.
+func zipVersions(local []string, remote []string) [][2]string {
+	union := map[string][2]string{}
+	for _, version := range local {
+		row := union[version]
+		row[0] = version
+		union[version] = row
+	}
+	for _, version := range remote {
+		row := union[version]
+		row[1] = version
+		union[version] = row
+	}
+
+	versions := make([]string, 0, len(union))
+	for version := range union {
+		versions = append(versions, version)
+	}
+	sort.Strings(versions)
+
+	rows := make([][2]string, 0, len(versions))
+	for _, version := range versions {
+		rows = append(rows, union[version])
+	}
+	return rows
+}
+
+func formatCell(version string) string {
+	if version == "" {
+		return " "
+	}
+	return "`" + version + "`"
+}
@@ -0,0 +1,295 @@
+package migration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgconn"
+	pgx "github.com/jackc/pgx/v4"
+	"github.com/supabase/cli/internal/utils"
+)
+
+// Apply sets up a freshly created database at url: the initial schema,
+// `.supabase/extensions.sql`, then the migrations in `.supabase/migrations`
+// wrapped in the `[scripts]` hooks. If seedBranch isn't empty, the seeds
+// configured for that branch are applied last. Each file is applied in its
+// own transaction, and the first failure aborts.
+func Apply(ctx context.Context, p utils.Program, url string, seedBranch string) error {
+	conn, err := pgx.Connect(ctx, url)
+	if err != nil {
+		return err
+	}
+	defer conn.Close(context.Background())
+
+	if err := ApplyInitialSchema(ctx, p, conn); err != nil {
+		return err
+	}
+
+	if err := utils.RunScripts(ctx, p, utils.BeforeMigrations, url); err != nil {
+		return err
+	}
+
+	if err := utils.MkdirIfNotExist(".supabase/migrations"); err != nil {
+		return err
+	}
+	migrations, err := utils.ReadMigrationsDir()
+	if err != nil {
+		return err
+	}
+	if _, err := conn.Exec(ctx, utils.CreateMigrationTableSql); err != nil {
+		return err
+	}
+
+	for i, migration := range migrations {
+		if i == 0 && isDeprecatedInit(migration.Name()) {
+			continue
+		}
+
+		p.Send(utils.StatusMsg("Applying migration " + utils.Bold(migration.Name()) + "..."))
+		progress := float64(i) / float64(len(migrations))
+		p.Send(utils.ProgressMsg(&progress))
+
+		path := ".supabase/migrations/" + migration.Name()
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		// Recorded in the same transaction, so `supabase db rollback` knows
+		// exactly which migrations were applied.
+		var version string
+		if matches := utils.MigrationFilePattern.FindStringSubmatch(migration.Name()); len(matches) == 2 {
+			version = matches[1]
+		}
+		if err := ExecMigration(ctx, conn, path, string(content), version); err != nil {
+			return err
+		}
+	}
+	p.Send(utils.ProgressMsg(nil))
+
+	if err := utils.RunScripts(ctx, p, utils.AfterMigrations, url); err != nil {
+		return err
+	}
+
+	if seedBranch != "" {
+		return Seed(ctx, p, conn, seedBranch)
+	}
+
+	return nil
+}
+
+// NOTE: To handle backward-compatibility. `<timestamp>_init.sql` as the first
+// migration (prev versions of the CLI) is deprecated.
+func isDeprecatedInit(name string) bool {
+	matches := regexp.MustCompile(`^([0-9]{14})_init\.sql$`).FindStringSubmatch(name)
+	if len(matches) != 2 {
+		return false
+	}
+	timestamp, err := strconv.ParseUint(matches[1], 10, 64)
+	return err == nil && timestamp < 20211209000000
+}
+
+// ApplyInitialSchema sets up the schemas managed by Supabase services, then
+// applies `.supabase/extensions.sql`.
+func ApplyInitialSchema(ctx context.Context, p utils.Program, conn *pgx.Conn) error {
+	p.Send(utils.StatusMsg("Setting up initial schema..."))
+	if err := ExecFile(ctx, conn, "initial schema", utils.InitialSchemaSql); err != nil {
+		return err
+	}
+
+	if extensionsSql, err := os.ReadFile(".supabase/extensions.sql"); errors.Is(err, os.ErrNotExist) {
+		// skip
+	} else if err != nil {
+		return err
+	} else {
+		p.Send(utils.StatusMsg("Applying " + utils.Bold(".supabase/extensions.sql") + "..."))
+		if err := ExecFile(ctx, conn, ".supabase/extensions.sql", string(extensionsSql)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ExecFile runs the statements in sql, read from the file name, in a single
+// transaction. A failing statement is reported with its file and line. Files
+// with statements that can't run in a transaction, e.g. `CREATE INDEX
+// CONCURRENTLY`, run one statement at a time instead, so a failure leaves the
+// statements before it applied.
+func ExecFile(ctx context.Context, conn *pgx.Conn, name string, sql string) error {
+	return ExecMigration(ctx, conn, name, sql, "")
+}
+
+// InsertVersion records the migration version as applied in the database's
+// migration history, creating the history table if needed.
+func InsertVersion(ctx context.Context, conn *pgx.Conn, version string) error {
+	if _, err := conn.Exec(ctx, utils.CreateMigrationTableSql); err != nil {
+		return err
+	}
+	_, err := conn.Exec(ctx, insertVersionSql, version)
+	return err
+}
+
+const insertVersionSql = "INSERT INTO supabase_migrations.schema_migrations(version) VALUES($1) ON CONFLICT DO NOTHING"
+
+// ExecMigration is ExecFile, also recording version in the migration history,
+// if set, when the file succeeds.
+func ExecMigration(ctx context.Context, conn *pgx.Conn, name string, sql string, version string) error {
+	// Files expect a fresh session, like they'd get from psql, so undo any
+	// settings (e.g. search_path) left behind by the previous file.
+	if _, err := conn.Exec(ctx, "RESET ALL"); err != nil {
+		return err
+	}
+
+	stmts := Split(sql)
+	if requiresNoTransaction(stmts) {
+		for _, stmt := range stmts {
+			if _, err := conn.Exec(ctx, stmt.Sql); err != nil {
+				return fmt.Errorf("Error running %s:%d: %w", name, errorLine(stmt, err), err)
+			}
+		}
+		if version != "" {
+			if _, err := conn.Exec(ctx, insertVersionSql, version); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(context.Background()) //nolint:errcheck
+
+	for _, stmt := range stmts {
+		if _, err := tx.Exec(ctx, stmt.Sql); err != nil {
+			return fmt.Errorf("Error running %s:%d: %w", name, errorLine(stmt, err), err)
+		}
+	}
+	if version != "" {
+		if _, err := tx.Exec(ctx, insertVersionSql, version); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+var (
+	leadingCommentPattern = regexp.MustCompile(`^(?:\s+|--[^\n]*|/\*(?s:.*?)\*/)+`)
+	concurrentlyPattern   = regexp.MustCompile(`(?i)^(?:CREATE\s+(?:UNIQUE\s+)?INDEX|DROP\s+INDEX|REINDEX\s+(?:\([^)]*\)\s*)?(?:INDEX|TABLE|SCHEMA|DATABASE|SYSTEM))\s+CONCURRENTLY\b`)
+)
+
+// requiresNoTransaction reports whether any of the statements fails inside a
+// transaction block, like building or dropping an index CONCURRENTLY.
+func requiresNoTransaction(stmts []Statement) bool {
+	for _, stmt := range stmts {
+		if concurrentlyPattern.MatchString(leadingCommentPattern.ReplaceAllString(stmt.Sql, "")) {
+			return true
+		}
+	}
+	return false
+}
+
+// errorLine returns the line of the error within the file, using the error
+// position Postgres reports within the statement if there is one.
+func errorLine(stmt Statement, err error) int {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) || pgErr.Position <= 0 {
+		return stmt.Line
+	}
+
+	runes := []rune(stmt.Sql)
+	pos := int(pgErr.Position) - 1
+	if pos > len(runes) {
+		pos = len(runes)
+	}
+	return stmt.Line + strings.Count(string(runes[:pos]), "\n")
+}
+
+type seedFile struct {
+	path string
+	// Empty for SQL files. CSV files are copied into this table.
+	table string
+}
+
+// getSeedFiles returns the seeds configured for the given branch, SQL files
+// first, with globs expanded. Paths are relative to `.supabase`. Patterns that
+// match no files are skipped, so the default `seed.sql` is optional.
+func getSeedFiles(branch string) ([]seedFile, error) {
+	patterns := utils.Config.Db.Seed.Files
+	csvSeeds := utils.Config.Db.Seed.Csv
+	if branchSeed, ok := utils.Config.Db.Seed.Branches[branch]; ok {
+		if branchSeed.Files != nil {
+			patterns = branchSeed.Files
+		}
+		if branchSeed.Csv != nil {
+			csvSeeds = branchSeed.Csv
+		}
+	}
+
+	files := []seedFile{}
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(filepath.Join(".supabase", pattern))
+		if err != nil {
+			return nil, fmt.Errorf("Invalid seed file pattern %s: %w", utils.Aqua(pattern), err)
+		}
+		// Glob results are sorted, so `seeds/*.sql` applies files in
+		// lexical order.
+		for _, match := range matches {
+			files = append(files, seedFile{path: match})
+		}
+	}
+	for _, csv := range csvSeeds {
+		files = append(files, seedFile{path: filepath.Join(".supabase", csv.File), table: csv.Table})
+	}
+
+	return files, nil
+}
+
+// Seed applies the seeds configured for branch, each file in its own
+// transaction. CSV files must have a header row.
+func Seed(ctx context.Context, p utils.Program, conn *pgx.Conn, branch string) error {
+	files, err := getSeedFiles(branch)
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		if file.table == "" {
+			p.Send(utils.StatusMsg("Applying " + utils.Bold(file.path) + "..."))
+
+			content, err := os.ReadFile(file.path)
+			if err != nil {
+				return err
+			}
+			if err := ExecFile(ctx, conn, file.path, string(content)); err != nil {
+				return err
+			}
+			continue
+		}
+
+		p.Send(utils.StatusMsg("Loading " + utils.Bold(file.path) + " into " + utils.Bold(file.table) + "..."))
+
+		if err := func() error {
+			f, err := os.Open(file.path)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+
+			_, err = conn.PgConn().CopyFrom(ctx, f, "COPY "+file.table+" FROM STDIN WITH (FORMAT csv, HEADER true)")
+			return err
+		}(); err != nil {
+			return fmt.Errorf("Error seeding database with %s: %w", file.path, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,110 @@
+package migration
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	pgx "github.com/jackc/pgx/v4"
+)
+
+// Runs against the Postgres at SUPABASE_TEST_DB_URL, e.g. the local database
+// started by `supabase start`. Skipped if unset.
+func connectTestDb(t *testing.T) *pgx.Conn {
+	url := os.Getenv("SUPABASE_TEST_DB_URL")
+	if url == "" {
+		t.Skip("SUPABASE_TEST_DB_URL is unset")
+	}
+
+	ctx := context.Background()
+	conn, err := pgx.Connect(ctx, url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close(ctx) })
+
+	if _, err := conn.Exec(ctx, "CREATE SCHEMA migration_test"); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _, _ = conn.Exec(ctx, "DROP SCHEMA migration_test CASCADE") })
+
+	return conn
+}
+
+func TestExecFile(t *testing.T) {
+	conn := connectTestDb(t)
+	ctx := context.Background()
+
+	t.Run("applies all statements", func(t *testing.T) {
+		if err := ExecFile(ctx, conn, "ok.sql", "CREATE TABLE migration_test.a (id int);\nINSERT INTO migration_test.a VALUES (1);"); err != nil {
+			t.Fatal(err)
+		}
+		var count int
+		if err := conn.QueryRow(ctx, "SELECT count(*) FROM migration_test.a").Scan(&count); err != nil {
+			t.Fatal(err)
+		}
+		if count != 1 {
+			t.Errorf("unexpected row count: %d", count)
+		}
+	})
+
+	t.Run("reports the failing line and rolls back", func(t *testing.T) {
+		err := ExecFile(ctx, conn, "bad.sql", "CREATE TABLE migration_test.b (id int);\n\nSELECT\n  missing_column\nFROM migration_test.b;")
+		if err == nil || !strings.Contains(err.Error(), "bad.sql:4:") {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var exists bool
+		if err := conn.QueryRow(ctx, "SELECT to_regclass('migration_test.b') IS NOT NULL").Scan(&exists); err != nil {
+			t.Fatal(err)
+		}
+		if exists {
+			t.Error("expected the transaction to be rolled back")
+		}
+	})
+
+	t.Run("reports runtime errors by statement", func(t *testing.T) {
+		err := ExecFile(ctx, conn, "runtime.sql", "SELECT 1;\nSELECT 1 / 0;")
+		if err == nil || !strings.Contains(err.Error(), "runtime.sql:2:") {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("builds indexes concurrently outside a transaction", func(t *testing.T) {
+		if err := ExecFile(ctx, conn, "index.sql", "CREATE TABLE migration_test.c (id int);\nCREATE INDEX CONCURRENTLY c_id_idx ON migration_test.c (id);"); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("resets session settings between files", func(t *testing.T) {
+		if err := ExecFile(ctx, conn, "a.sql", "SELECT pg_catalog.set_config('search_path', '', false);"); err != nil {
+			t.Fatal(err)
+		}
+		if err := ExecFile(ctx, conn, "b.sql", "SELECT 1;"); err != nil {
+			t.Fatal(err)
+		}
+		var searchPath string
+		if err := conn.QueryRow(ctx, "SHOW search_path").Scan(&searchPath); err != nil {
+			t.Fatal(err)
+		}
+		if searchPath == "" {
+			t.Error("expected search_path to be reset")
+		}
+	})
+}
+
+func TestRequiresNoTransaction(t *testing.T) {
+	for sql, expected := range map[string]bool{
+		"CREATE INDEX CONCURRENTLY a_idx ON a (id);":                             true,
+		"-- Build without locking.\ncreate unique index concurrently on a (id);": true,
+		"DROP INDEX CONCURRENTLY IF EXISTS a_idx;":                               true,
+		"REINDEX (VERBOSE) TABLE CONCURRENTLY a;":                                true,
+		"CREATE INDEX a_idx ON a (id);":                                          false,
+		"REFRESH MATERIALIZED VIEW CONCURRENTLY v;":                              false,
+		"COMMENT ON TABLE a IS 'CREATE INDEX CONCURRENTLY';":                     false,
+	} {
+		if actual := requiresNoTransaction(Split(sql)); actual != expected {
+			t.Errorf("%q: expected %v, got %v", sql, expected, actual)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package repair
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+
+	pgx "github.com/jackc/pgx/v4"
+	"github.com/supabase/cli/internal/utils"
+)
+
+const (
+	Applied  = "applied"
+	Reverted = "reverted"
+)
+
+var ctx = context.Background()
+
+func Run(status string, versions []string) error {
+	// Sanity checks.
+	{
+		if status != Applied && status != Reverted {
+			return errors.New("Invalid status " + utils.Aqua(status) + ". Must be one of: " + Applied + ", " + Reverted + ".")
+		}
+		for _, version := range versions {
+			if matched, err := regexp.MatchString(`^[0-9]+$`, version); err != nil {
+				return err
+			} else if !matched {
+				return errors.New("Invalid migration version " + utils.Aqua(version) + ". Must be a timestamp like 20220101000000.")
+			}
+		}
+	}
+
+	url, err := utils.LoadRemoteDbUrl()
+	if err != nil {
+		return err
+	}
+
+	conn, err := pgx.Connect(ctx, url)
+	if err != nil {
+		return err
+	}
+	defer conn.Close(context.Background())
+
+	if err := func() error {
+		tx, err := conn.Begin(ctx)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback(context.Background()) //nolint:errcheck
+
+		if _, err := tx.Exec(ctx, utils.CreateMigrationTableSql); err != nil {
+			return err
+		}
+
+		for _, version := range versions {
+			switch status {
+			case Applied:
+				if _, err := tx.Exec(ctx, "INSERT INTO supabase_migrations.schema_migrations(version) VALUES($1) ON CONFLICT DO NOTHING", version); err != nil {
+					return fmt.Errorf("%w; while marking migration %s as applied", err, version)
+				}
+			case Reverted:
+				if _, err := tx.Exec(ctx, "DELETE FROM supabase_migrations.schema_migrations WHERE version = $1", version); err != nil {
+					return fmt.Errorf("%w; while marking migration %s as reverted", err, version)
+				}
+			}
+		}
+
+		return tx.Commit(ctx)
+	}(); err != nil {
+		return fmt.Errorf("Error repairing migration history: %w", err)
+	}
+
+	fmt.Println("Finished " + utils.Aqua("supabase migration repair") + ".")
+	return nil
+}
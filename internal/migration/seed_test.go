@@ -0,0 +1,76 @@
+package migration
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/BurntSushi/toml"
+	"github.com/supabase/cli/internal/utils"
+)
+
+func TestGetSeedFiles(t *testing.T) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = os.Chdir(cwd)
+		utils.Config.Db.Seed.Files = nil
+		utils.Config.Db.Seed.Csv = nil
+		utils.Config.Db.Seed.Branches = nil
+	})
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{".supabase/seed.sql", ".supabase/seeds/02_posts.sql", ".supabase/seeds/01_users.sql"} {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := toml.Decode(`
+files = ["seed.sql", "seeds/*.sql", "missing.sql"]
+csv = [{ file = "seeds/countries.csv", table = "public.countries" }]
+
+[branches.feature]
+files = ["seeds/02_posts.sql"]
+`, &utils.Config.Db.Seed); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("expands globs in order", func(t *testing.T) {
+		files, err := getSeedFiles("main")
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := []seedFile{
+			{path: ".supabase/seed.sql"},
+			{path: ".supabase/seeds/01_users.sql"},
+			{path: ".supabase/seeds/02_posts.sql"},
+			{path: ".supabase/seeds/countries.csv", table: "public.countries"},
+		}
+		if !reflect.DeepEqual(files, expected) {
+			t.Errorf("unexpected seed files: %v", files)
+		}
+	})
+
+	t.Run("branch seeds override defaults", func(t *testing.T) {
+		files, err := getSeedFiles("feature")
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Unset fields fall back to [db.seed].
+		expected := []seedFile{
+			{path: ".supabase/seeds/02_posts.sql"},
+			{path: ".supabase/seeds/countries.csv", table: "public.countries"},
+		}
+		if !reflect.DeepEqual(files, expected) {
+			t.Errorf("unexpected seed files: %v", files)
+		}
+	})
+}
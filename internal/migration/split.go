@@ -0,0 +1,246 @@
+package migration
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Statement is a single SQL statement and the line it starts on in its file.
+type Statement struct {
+	Sql  string
+	Line int
+}
+
+// Split splits sql into statements on top-level semicolons, the way psql
+// does. Semicolons in comments, quoted strings and identifiers, dollar-quoted
+// bodies and `BEGIN ATOMIC ... END` function bodies don't end a statement.
+// Statements with nothing but whitespace and comments are dropped.
+func Split(sql string) []Statement {
+	src := []rune(sql)
+	stmts := []Statement{}
+
+	line := 1
+	start, startLine := -1, 0
+	// Depth of `BEGIN ATOMIC` bodies, counting the `CASE ... END`s within.
+	atomicDepth := 0
+	prevWord := ""
+
+	advance := func(i int) int {
+		if src[i] == '\n' {
+			line++
+		}
+		return i + 1
+	}
+	markStart := func(i int) {
+		if start < 0 {
+			start, startLine = i, line
+		}
+	}
+
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == '-' && i+1 < len(src) && src[i+1] == '-':
+			for i < len(src) && src[i] != '\n' {
+				i = advance(i)
+			}
+		case c == '/' && i+1 < len(src) && src[i+1] == '*':
+			depth := 0
+			for i < len(src) {
+				if src[i] == '/' && i+1 < len(src) && src[i+1] == '*' {
+					depth++
+					i = advance(advance(i))
+				} else if src[i] == '*' && i+1 < len(src) && src[i+1] == '/' {
+					depth--
+					i = advance(advance(i))
+					if depth == 0 {
+						break
+					}
+				} else {
+					i = advance(i)
+				}
+			}
+		case c == '\'' || c == '"':
+			markStart(i)
+			escapes := c == '\'' && i > 0 && (src[i-1] == 'E' || src[i-1] == 'e') && (i < 2 || !isIdentRune(src[i-2]))
+			i = advance(i)
+			for i < len(src) {
+				if escapes && src[i] == '\\' && i+1 < len(src) {
+					i = advance(advance(i))
+				} else if src[i] == c && i+1 < len(src) && src[i+1] == c {
+					i = advance(advance(i))
+				} else if src[i] == c {
+					i = advance(i)
+					break
+				} else {
+					i = advance(i)
+				}
+			}
+			prevWord = ""
+		case c == '$' && (i == 0 || !isIdentRune(src[i-1])) && dollarTag(src[i:]) != "":
+			markStart(i)
+			tag := []rune(dollarTag(src[i:]))
+			i += len(tag)
+			for i < len(src) && !hasPrefix(src[i:], tag) {
+				i = advance(i)
+			}
+			if i < len(src) {
+				i += len(tag)
+			}
+			prevWord = ""
+		case isIdentRune(c) && !unicode.IsDigit(c) && c != '$':
+			markStart(i)
+			j := i
+			for j < len(src) && isIdentRune(src[j]) {
+				j++
+			}
+			word := strings.ToUpper(string(src[i:j]))
+			switch {
+			case word == "ATOMIC" && prevWord == "BEGIN":
+				atomicDepth++
+			case word == "CASE" && atomicDepth > 0:
+				atomicDepth++
+			case word == "END" && atomicDepth > 0:
+				atomicDepth--
+			}
+			prevWord = word
+			for i < j {
+				i = advance(i)
+			}
+		case c == ';' && atomicDepth == 0:
+			if start >= 0 {
+				stmts = append(stmts, Statement{Sql: string(src[start : i+1]), Line: startLine})
+			}
+			start = -1
+			prevWord = ""
+			i = advance(i)
+		default:
+			if !unicode.IsSpace(c) {
+				markStart(i)
+				prevWord = ""
+			}
+			i = advance(i)
+		}
+	}
+
+	if start >= 0 {
+		stmts = append(stmts, Statement{Sql: strings.TrimRightFunc(string(src[start:]), unicode.IsSpace), Line: startLine})
+	}
+
+	return stmts
+}
+
+// Normalize strips comments and the contents of string literals and
+// dollar-quoted bodies from sql, and collapses whitespace, so that it can be
+// pattern matched without false positives from bodies and comments.
+func Normalize(sql string) string {
+	var out strings.Builder
+	src := []rune(sql)
+	space := false
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == '-' && i+1 < len(src) && src[i+1] == '-':
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+			space = true
+		case c == '/' && i+1 < len(src) && src[i+1] == '*':
+			depth := 0
+			for i < len(src) {
+				if src[i] == '/' && i+1 < len(src) && src[i+1] == '*' {
+					depth++
+					i += 2
+				} else if src[i] == '*' && i+1 < len(src) && src[i+1] == '/' {
+					depth--
+					i += 2
+					if depth == 0 {
+						break
+					}
+				} else {
+					i++
+				}
+			}
+			space = true
+		case c == '\'':
+			for i++; i < len(src); i++ {
+				if src[i] == '\'' && i+1 < len(src) && src[i+1] == '\'' {
+					i++
+				} else if src[i] == '\'' {
+					i++
+					break
+				}
+			}
+			writeToken(&out, &space, "''")
+		case c == '"':
+			// Quoted identifiers are kept, since rules report them.
+			j := i + 1
+			for ; j < len(src); j++ {
+				if src[j] == '"' && j+1 < len(src) && src[j+1] == '"' {
+					j++
+				} else if src[j] == '"' {
+					j++
+					break
+				}
+			}
+			writeToken(&out, &space, string(src[i:j]))
+			i = j
+		case c == '$' && (i == 0 || !isIdentRune(src[i-1])) && dollarTag(src[i:]) != "":
+			tag := []rune(dollarTag(src[i:]))
+			i += len(tag)
+			for i < len(src) && !hasPrefix(src[i:], tag) {
+				i++
+			}
+			if i < len(src) {
+				i += len(tag)
+			}
+			writeToken(&out, &space, "$$")
+		case unicode.IsSpace(c):
+			space = true
+			i++
+		default:
+			writeToken(&out, &space, string(c))
+			i++
+		}
+	}
+	return out.String()
+}
+
+func writeToken(out *strings.Builder, space *bool, token string) {
+	if *space && out.Len() > 0 {
+		out.WriteByte(' ')
+	}
+	*space = false
+	out.WriteString(token)
+}
+
+func isIdentRune(c rune) bool {
+	return c == '_' || c == '$' || unicode.IsLetter(c) || unicode.IsDigit(c)
+}
+
+func hasPrefix(src []rune, prefix []rune) bool {
+	if len(src) < len(prefix) {
+		return false
+	}
+	for i := range prefix {
+		if src[i] != prefix[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// dollarTag returns the `$tag$` src starts with, or "" if it doesn't start
+// with one.
+func dollarTag(src []rune) string {
+	for i := 1; i < len(src); i++ {
+		c := src[i]
+		if c == '$' {
+			return string(src[:i+1])
+		}
+		if !(c == '_' || unicode.IsLetter(c) || (i > 1 && unicode.IsDigit(c))) {
+			return ""
+		}
+	}
+	return ""
+}
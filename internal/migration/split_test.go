@@ -0,0 +1,59 @@
+package migration
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplit(t *testing.T) {
+	cases := []struct {
+		name     string
+		sql      string
+		expected []Statement
+	}{
+		{
+			name: "statements and lines",
+			sql:  "-- header\nCREATE TABLE a (id int);\n\nINSERT INTO a\nVALUES (1);\n",
+			expected: []Statement{
+				{Sql: "CREATE TABLE a (id int);", Line: 2},
+				{Sql: "INSERT INTO a\nVALUES (1);", Line: 4},
+			},
+		},
+		{
+			name: "semicolons in strings, identifiers and comments",
+			sql:  "SELECT 'a;''b', E'c\\';', \"d;\"\"e\" /* f; /* g; */ */ -- h;\n;",
+			expected: []Statement{
+				{Sql: "SELECT 'a;''b', E'c\\';', \"d;\"\"e\" /* f; /* g; */ */ -- h;\n;", Line: 1},
+			},
+		},
+		{
+			name: "dollar-quoted bodies",
+			sql:  "CREATE FUNCTION f() RETURNS int AS $body$ SELECT 1; $x$ $body$ LANGUAGE sql;\nSELECT $1, a$b;",
+			expected: []Statement{
+				{Sql: "CREATE FUNCTION f() RETURNS int AS $body$ SELECT 1; $x$ $body$ LANGUAGE sql;", Line: 1},
+				{Sql: "SELECT $1, a$b;", Line: 2},
+			},
+		},
+		{
+			name: "begin atomic bodies",
+			sql:  "CREATE FUNCTION f() RETURNS int LANGUAGE sql\nBEGIN ATOMIC\n  SELECT CASE WHEN true THEN 1 END;\n  SELECT 2;\nEND;\nSELECT 3",
+			expected: []Statement{
+				{Sql: "CREATE FUNCTION f() RETURNS int LANGUAGE sql\nBEGIN ATOMIC\n  SELECT CASE WHEN true THEN 1 END;\n  SELECT 2;\nEND;", Line: 1},
+				{Sql: "SELECT 3", Line: 6},
+			},
+		},
+		{
+			name:     "comments only",
+			sql:      "-- nothing\n/* here */\n;",
+			expected: []Statement{},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if actual := Split(c.sql); !reflect.DeepEqual(actual, c.expected) {
+				t.Errorf("unexpected statements:\n%#v\n\nexpected:\n%#v", actual, c.expected)
+			}
+		})
+	}
+}
@@ -1,19 +1,23 @@
 package list
 
 import (
+	"context"
 	"crypto/md5"
-	"encoding/json"
-	"errors"
 	"fmt"
-	"io"
-	"net/http"
 	"os"
 	"strings"
 
 	"github.com/charmbracelet/glamour"
+	"github.com/supabase/cli/internal/api"
 	"github.com/supabase/cli/internal/utils"
 )
 
+// Secret values are never printed, only their digests.
+type secretDigest struct {
+	Name   string `json:"name"`
+	Digest string `json:"digest"`
+}
+
 func Run() error {
 	// 1. Sanity checks.
 	{
@@ -33,42 +37,22 @@ func Run() error {
 		}
 		projectRef := string(projectRefBytes)
 
-		accessToken, err := utils.LoadAccessToken()
+		client, err := api.NewDefaultClient()
 		if err != nil {
 			return err
 		}
 
-		req, err := http.NewRequest("GET", "https://api.supabase.io/v1/projects/"+projectRef+"/secrets", nil)
-		if err != nil {
-			return err
-		}
-		req.Header.Add("Authorization", "Bearer "+string(accessToken))
-		resp, err := http.DefaultClient.Do(req)
+		secrets, err := client.ListSecrets(context.Background(), projectRef)
 		if err != nil {
-			return err
+			return fmt.Errorf("Unexpected error retrieving project secrets: %w", err)
 		}
-		defer resp.Body.Close()
 
-		if resp.StatusCode != 200 {
-			body, err := io.ReadAll(resp.Body)
-			if err != nil {
-				return fmt.Errorf("Unexpected error retrieving project secrets: %w", err)
+		if utils.OutputFormat.Value != utils.OutputPretty {
+			digests := []secretDigest{}
+			for _, secret := range secrets {
+				digests = append(digests, secretDigest{Name: secret.Name, Digest: fmt.Sprintf("%x", md5.Sum([]byte(secret.Value)))})
 			}
-
-			return errors.New("Unexpected error retrieving project secrets: " + string(body))
-		}
-
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return err
-		}
-
-		var secrets []struct {
-			Name  string
-			Value string
-		}
-		if err := json.Unmarshal(body, &secrets); err != nil {
-			return err
+			return utils.EncodeOutput(utils.OutputFormat.Value, os.Stdout, digests)
 		}
 
 		table := `|NAME|DIGEST|
@@ -1,16 +1,14 @@
 package set
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
 	"errors"
 	"fmt"
-	"net/http"
 	"os"
 	"strings"
-	"io"
 
 	"github.com/joho/godotenv"
+	"github.com/supabase/cli/internal/api"
 	"github.com/supabase/cli/internal/utils"
 )
 
@@ -33,24 +31,19 @@ func Run(envFilePath string, args []string) error {
 		}
 		projectRef := string(projectRefBytes)
 
-		accessToken, err := utils.LoadAccessToken()
+		client, err := api.NewDefaultClient()
 		if err != nil {
 			return err
 		}
 
-		type Secret struct {
-			Name  string `json:"name"`
-			Value string `json:"value"`
-		}
-
-		var secrets []Secret
+		var secrets []api.Secret
 		if envFilePath != "" {
 			envMap, err := godotenv.Read(envFilePath)
 			if err != nil {
 				return err
 			}
 			for name, value := range envMap {
-				secret := Secret{
+				secret := api.Secret{
 					Name:  name,
 					Value: value,
 				}
@@ -65,7 +58,7 @@ func Run(envFilePath string, args []string) error {
 					return errors.New("Invalid secret pair: " + utils.Aqua(pair) + ". Must be NAME=VALUE.")
 				}
 
-				secret := Secret{
+				secret := api.Secret{
 					Name:  name,
 					Value: value,
 				}
@@ -73,33 +66,11 @@ func Run(envFilePath string, args []string) error {
 			}
 		}
 
-		secretsBytes, err := json.Marshal(secrets)
-		if err != nil {
-			return err
-		}
-		reqBody := bytes.NewReader(secretsBytes)
-
-		req, err := http.NewRequest("POST", "https://api.supabase.io/v1/projects/"+projectRef+"/secrets", reqBody)
-		if err != nil {
-			return err
-		}
-		req.Header.Add("Authorization", "Bearer "+string(accessToken))
-		req.Header.Add("Content-Type", "application/json")
-		resp, err := http.DefaultClient.Do(req)
-		if err != nil {
-			return err
-		}
-
-		body, err := io.ReadAll(resp.Body)
-		if(err != nil) {
-			return errors.New("Error reading response body: " + err.Error())
-		}
-
-		if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
-			return errors.New("Unexpected error setting project secrets: " + string(body))
+		if err := client.CreateSecrets(context.Background(), projectRef, secrets); err != nil {
+			return fmt.Errorf("Unexpected error setting project secrets: %w", err)
 		}
-		defer resp.Body.Close()
 	}
+
 	fmt.Println("Finished " + utils.Aqua("supabase secrets set") + ".")
 	return nil
-}
\ No newline at end of file
+}
@@ -1,14 +1,11 @@
 package unset
 
 import (
-	"bytes"
-	"encoding/json"
-	"errors"
+	"context"
 	"fmt"
-	"io"
-	"net/http"
 	"os"
 
+	"github.com/supabase/cli/internal/api"
 	"github.com/supabase/cli/internal/utils"
 )
 
@@ -31,36 +28,13 @@ func Run(args []string) error {
 		}
 		projectRef := string(projectRefBytes)
 
-		accessToken, err := utils.LoadAccessToken()
+		client, err := api.NewDefaultClient()
 		if err != nil {
 			return err
 		}
 
-		secretsNamesBytes, err := json.Marshal(args)
-		if err != nil {
-			return err
-		}
-		reqBody := bytes.NewReader(secretsNamesBytes)
-
-		req, err := http.NewRequest("DELETE", "https://api.supabase.io/v1/projects/"+projectRef+"/secrets", reqBody)
-		if err != nil {
-			return err
-		}
-		req.Header.Add("Authorization", "Bearer "+string(accessToken))
-		req.Header.Add("Content-Type", "application/json")
-		resp, err := http.DefaultClient.Do(req)
-		if err != nil {
-			return err
-		}
-		defer resp.Body.Close()
-
-		if resp.StatusCode != 200 {
-			body, err := io.ReadAll(resp.Body)
-			if err != nil {
-				return fmt.Errorf("Unexpected error unsetting project secrets: %w", err)
-			}
-
-			return errors.New("Unexpected error unsetting project secrets: " + string(body))
+		if err := client.DeleteSecrets(context.Background(), projectRef, args); err != nil {
+			return fmt.Errorf("Unexpected error unsetting project secrets: %w", err)
 		}
 	}
 
@@ -0,0 +1,103 @@
+package start
+
+import (
+	"errors"
+
+	"github.com/supabase/cli/internal/utils"
+)
+
+// Services started alongside the database, by the names used in `--exclude`
+// and `--only`. The database is always started.
+var allServices = []string{"kong", "gotrue", "inbucket", "realtime", "postgrest", "storage", "pgmeta", "studio"}
+
+// Services that don't work without other services. Services behind Kong only
+// publish ports through it, so they're unreachable, and never healthy, without
+// it.
+var serviceDependencies = map[string][]string{
+	"gotrue":    {"kong"},
+	"realtime":  {"kong"},
+	"postgrest": {"kong"},
+	"storage":   {"kong", "postgrest"},
+	"pgmeta":    {"kong"},
+	"studio":    {"pgmeta"},
+}
+
+// selectServices returns the services to start. `only` takes precedence over
+// the `enabled` keys in the config, while `exclude` is applied on top of them.
+func selectServices(exclude []string, only []string) (map[string]bool, error) {
+	if len(exclude) > 0 && len(only) > 0 {
+		return nil, errors.New("Cannot use both " + utils.Aqua("--exclude") + " and " + utils.Aqua("--only") + ".")
+	}
+	for _, name := range append(append([]string{}, exclude...), only...) {
+		if !isService(name) {
+			return nil, errors.New("Unknown service " + utils.Aqua(name) + ". Must be one of kong, gotrue, inbucket, realtime, postgrest, storage, pgmeta or studio.")
+		}
+	}
+
+	services := map[string]bool{}
+	if len(only) > 0 {
+		for _, name := range only {
+			services[name] = true
+		}
+	} else {
+		for _, name := range allServices {
+			services[name] = true
+		}
+		services["kong"] = *utils.Config.Kong.Enabled
+		services["gotrue"] = *utils.Config.Auth.Enabled
+		services["inbucket"] = *utils.Config.Inbucket.Enabled
+		services["realtime"] = *utils.Config.Realtime.Enabled
+		services["postgrest"] = *utils.Config.Postgrest.Enabled
+		services["storage"] = *utils.Config.Storage.Enabled
+		// pg-meta is only started as Studio's backend.
+		services["studio"] = *utils.Config.Studio.Enabled
+		services["pgmeta"] = *utils.Config.Pgmeta.Enabled && *utils.Config.Studio.Enabled
+		for _, name := range exclude {
+			services[name] = false
+		}
+	}
+
+	for _, name := range allServices {
+		for _, dependency := range serviceDependencies[name] {
+			if services[name] && !services[dependency] {
+				return nil, errors.New("Cannot start " + utils.Aqua(name) + " without " + utils.Aqua(dependency) + ".")
+			}
+		}
+	}
+
+	return services, nil
+}
+
+// serviceImages returns the images to pull for the given services, including
+// the database and, with Kong, the Functions relay it routes to.
+func serviceImages(services map[string]bool) []string {
+	images := []string{utils.DbImage}
+	for _, service := range []struct {
+		name  string
+		image string
+	}{
+		{"kong", utils.KongImage},
+		{"kong", utils.DenoRelayImage},
+		{"gotrue", utils.GotrueImage},
+		{"inbucket", utils.InbucketImage},
+		{"realtime", utils.RealtimeImage},
+		{"postgrest", utils.PostgrestImage},
+		{"storage", utils.StorageImage},
+		{"pgmeta", utils.PgmetaImage},
+		{"studio", utils.StudioImage},
+	} {
+		if services[service.name] {
+			images = append(images, service.image)
+		}
+	}
+	return images
+}
+
+func isService(name string) bool {
+	for _, service := range allServices {
+		if service == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,120 @@
+package start
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/supabase/cli/internal/utils"
+)
+
+func setEnabled(studio, inbucket, realtime, storage, auth bool) {
+	enabled := true
+	utils.Config.Kong.Enabled = &enabled
+	utils.Config.Postgrest.Enabled = &enabled
+	utils.Config.Pgmeta.Enabled = &enabled
+	utils.Config.Studio.Enabled = &studio
+	utils.Config.Inbucket.Enabled = &inbucket
+	utils.Config.Realtime.Enabled = &realtime
+	utils.Config.Storage.Enabled = &storage
+	utils.Config.Auth.Enabled = &auth
+}
+
+func TestSelectServices(t *testing.T) {
+	t.Run("all by default", func(t *testing.T) {
+		setEnabled(true, true, true, true, true)
+		services, err := selectServices(nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, name := range allServices {
+			if !services[name] {
+				t.Errorf("expected %s to start", name)
+			}
+		}
+	})
+
+	t.Run("exclude and config", func(t *testing.T) {
+		setEnabled(false, true, true, true, true)
+		services, err := selectServices([]string{"realtime"}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for name, want := range map[string]bool{"studio": false, "pgmeta": false, "realtime": false, "storage": true, "kong": true} {
+			if services[name] != want {
+				t.Errorf("%s: expected %v, got %v", name, want, services[name])
+			}
+		}
+	})
+
+	t.Run("config only", func(t *testing.T) {
+		setEnabled(false, false, false, false, true)
+		disabled := false
+		utils.Config.Pgmeta.Enabled = &disabled
+		services, err := selectServices(nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, name := range allServices {
+			want := name == "kong" || name == "postgrest" || name == "gotrue"
+			if services[name] != want {
+				t.Errorf("%s: expected %v, got %v", name, want, services[name])
+			}
+		}
+
+		utils.Config.Kong.Enabled = &disabled
+		if _, err := selectServices(nil, nil); err == nil {
+			t.Error("expected an error for services that need kong")
+		}
+	})
+
+	t.Run("only ignores config", func(t *testing.T) {
+		setEnabled(true, true, true, true, false)
+		services, err := selectServices(nil, []string{"kong", "postgrest", "gotrue"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !services["gotrue"] || !services["postgrest"] || !services["kong"] || services["studio"] {
+			t.Errorf("unexpected services: %v", services)
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		setEnabled(true, true, true, true, true)
+		for _, args := range [][2][]string{
+			{{"studio"}, {"kong"}},
+			{{"db"}, nil},
+			{{"postgrest"}, nil},
+			{nil, {"studio"}},
+			{nil, {"postgrest", "gotrue"}},
+			{{"kong"}, nil},
+		} {
+			if _, err := selectServices(args[0], args[1]); err == nil {
+				t.Errorf("expected error for exclude=%v only=%v", args[0], args[1])
+			}
+		}
+	})
+}
+
+func TestKongConfigRoutes(t *testing.T) {
+	var buf bytes.Buffer
+	if err := kongConfigTemplate.Execute(&buf, struct {
+		ProjectId, AnonKey, ServiceRoleKey string
+		Services                           map[string]bool
+	}{
+		ProjectId: "test",
+		Services:  map[string]bool{"kong": true, "postgrest": true},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	out := buf.String()
+	if !strings.Contains(out, "services:\n  - name: rest-v1\n") || !strings.Contains(out, "/functions/v1/") {
+		t.Errorf("missing routes in:\n%s", out)
+	}
+	for _, route := range []string{"/auth/v1/", "/realtime/v1/", "/storage/v1/", "/pg/"} {
+		if strings.Contains(out, route) {
+			t.Errorf("unexpected route %s in:\n%s", route, out)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package start
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestSetAsideBranch(t *testing.T) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(cwd) })
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(".supabase/branches/feature", 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(".supabase/branches/feature/dump.sql", []byte("CREATE TABLE t ();"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	dumpPath, err := setAsideBranch("feature")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dump, err := os.ReadFile(dumpPath); err != nil || string(dump) != "CREATE TABLE t ();" {
+		t.Errorf("expected the dump to be kept at %s: %v", dumpPath, err)
+	}
+	if _, err := os.Stat(".supabase/branches/feature"); !os.IsNotExist(err) {
+		t.Errorf("expected the branch to be moved out of .supabase/branches: %v", err)
+	}
+}
+
+func TestRestoreHint(t *testing.T) {
+	hint := restoreHint("feature", ".supabase/failed_branches/feature_20220101000000/dump.sql")
+	// Loads the dump unfiltered, keeping auth and storage data.
+	if strings.Contains(hint, "supabase db restore") || !strings.Contains(hint, "psql") || !strings.Contains(hint, "< .supabase/failed_branches/feature_20220101000000/dump.sql") {
+		t.Errorf("unexpected hint: %s", hint)
+	}
+}
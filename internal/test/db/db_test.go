@@ -0,0 +1,44 @@
+package db
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTestStatements(t *testing.T) {
+	t.Run("drops the transaction around the file", func(t *testing.T) {
+		sql := "-- Setup\nBEGIN;\nSELECT plan(1);\nSELECT ok(true);\nSELECT * FROM finish();\nROLLBACK;\n"
+		stmts, err := testStatements("a.sql", sql)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(stmts) != 3 || !strings.Contains(stmts[0].Sql, "plan(1)") || !strings.Contains(stmts[2].Sql, "finish()") {
+			t.Errorf("unexpected statements: %+v", stmts)
+		}
+	})
+
+	t.Run("allows savepoints", func(t *testing.T) {
+		sql := "SAVEPOINT a;\nSELECT ok(true);\nROLLBACK TO SAVEPOINT a;\nRELEASE SAVEPOINT a;\n"
+		if stmts, err := testStatements("a.sql", sql); err != nil || len(stmts) != 4 {
+			t.Errorf("unexpected result: %+v, %v", stmts, err)
+		}
+	})
+
+	t.Run("allows transaction keywords in function bodies", func(t *testing.T) {
+		sql := "DO $$ BEGIN PERFORM 1; END $$;\nSELECT ok(true);\n"
+		if stmts, err := testStatements("a.sql", sql); err != nil || len(stmts) != 2 {
+			t.Errorf("unexpected result: %+v, %v", stmts, err)
+		}
+	})
+
+	for _, sql := range []string{
+		"BEGIN;\nSELECT ok(true);\nCOMMIT;\n",
+		"SELECT ok(true);\nCOMMIT;\n",
+		"BEGIN;\nSELECT ok(true);\nROLLBACK;\nSELECT ok(true);\n",
+		"SELECT ok(true);\n  /* done */ end;\n",
+	} {
+		if _, err := testStatements("a.sql", sql); err == nil || !strings.Contains(err.Error(), "transaction control") {
+			t.Errorf("expected an error for %q, got %v", sql, err)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package db
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type testResult struct {
+	Ok          bool
+	Description string
+	Diagnostics []string
+}
+
+// fileResult is the TAP output of one test file.
+type fileResult struct {
+	Path string
+	// -1 if the file didn't declare a plan.
+	Plan  int
+	Tests []testResult
+	// Set if the file failed to run, e.g. on a syntax error.
+	Err error
+}
+
+var (
+	planPattern   = regexp.MustCompile(`^1\.\.(\d+)`)
+	resultPattern = regexp.MustCompile(`^(not )?ok\b(?:\s+\d+)?(?:\s*-)?\s*(.*)$`)
+	todoPattern   = regexp.MustCompile(`(?i)#\s*TODO\b`)
+)
+
+// parseTap parses the TAP lines output by pgTAP functions.
+func parseTap(path string, lines []string) fileResult {
+	result := fileResult{Path: path, Plan: -1}
+	for _, line := range lines {
+		line = strings.TrimRight(line, " \t")
+		if matches := planPattern.FindStringSubmatch(line); matches != nil {
+			result.Plan, _ = strconv.Atoi(matches[1])
+		} else if matches := resultPattern.FindStringSubmatch(line); matches != nil {
+			result.Tests = append(result.Tests, testResult{
+				// Failing TODO tests are expected to fail.
+				Ok:          matches[1] == "" || todoPattern.MatchString(matches[2]),
+				Description: matches[2],
+			})
+		} else if strings.HasPrefix(line, "#") && len(result.Tests) > 0 {
+			last := &result.Tests[len(result.Tests)-1]
+			last.Diagnostics = append(last.Diagnostics, strings.TrimSpace(strings.TrimPrefix(line, "#")))
+		}
+	}
+	return result
+}
+
+// problem describes why the file failed as a whole, or is "" if it didn't.
+func (r fileResult) problem() string {
+	if r.Err != nil {
+		return r.Err.Error()
+	}
+	if len(r.Tests) == 0 {
+		return "No tests ran."
+	}
+	if r.Plan >= 0 && r.Plan != len(r.Tests) {
+		return fmt.Sprintf("Planned %d tests but ran %d.", r.Plan, len(r.Tests))
+	}
+	return ""
+}
+
+func (r fileResult) failed() bool {
+	if r.problem() != "" {
+		return true
+	}
+	for _, test := range r.Tests {
+		if !test.Ok {
+			return true
+		}
+	}
+	return false
+}
+
+// writeTap writes results as a TAP 13 stream with one subtest per file.
+func writeTap(w io.Writer, results []fileResult) {
+	fmt.Fprintln(w, "TAP version 13")
+	fmt.Fprintf(w, "1..%d\n", len(results))
+	for i, result := range results {
+		fmt.Fprintln(w, "# Subtest: "+result.Path)
+		if result.Plan >= 0 {
+			fmt.Fprintf(w, "    1..%d\n", result.Plan)
+		}
+		for j, test := range result.Tests {
+			status := "ok"
+			if !test.Ok {
+				status = "not ok"
+			}
+			fmt.Fprintf(w, "    %s %d - %s\n", status, j+1, test.Description)
+			for _, diag := range test.Diagnostics {
+				fmt.Fprintln(w, "    # "+diag)
+			}
+		}
+
+		status := "ok"
+		if result.failed() {
+			status = "not ok"
+		}
+		fmt.Fprintf(w, "%s %d - %s\n", status, i+1, result.Path)
+		if problem := result.problem(); problem != "" {
+			fmt.Fprintln(w, "# "+strings.ReplaceAll(problem, "\n", "\n# "))
+		}
+	}
+}
+
+type (
+	junitTestSuites struct {
+		XMLName  xml.Name         `xml:"testsuites"`
+		Tests    int              `xml:"tests,attr"`
+		Failures int              `xml:"failures,attr"`
+		Suites   []junitTestSuite `xml:"testsuite"`
+	}
+
+	junitTestSuite struct {
+		Name     string          `xml:"name,attr"`
+		Tests    int             `xml:"tests,attr"`
+		Failures int             `xml:"failures,attr"`
+		Errors   int             `xml:"errors,attr"`
+		Cases    []junitTestCase `xml:"testcase"`
+		Error    *junitFailure   `xml:"error,omitempty"`
+	}
+
+	junitTestCase struct {
+		Name      string        `xml:"name,attr"`
+		Classname string        `xml:"classname,attr"`
+		Failure   *junitFailure `xml:"failure,omitempty"`
+	}
+
+	junitFailure struct {
+		Message string `xml:"message,attr"`
+		Text    string `xml:",chardata"`
+	}
+)
+
+// writeJunit writes results as JUnit XML with one test suite per file.
+func writeJunit(w io.Writer, results []fileResult) error {
+	suites := junitTestSuites{}
+	for _, result := range results {
+		suite := junitTestSuite{Name: result.Path, Tests: len(result.Tests)}
+		for i, test := range result.Tests {
+			testCase := junitTestCase{Name: fmt.Sprintf("%d - %s", i+1, test.Description), Classname: result.Path}
+			if !test.Ok {
+				testCase.Failure = &junitFailure{Message: "not ok", Text: strings.Join(test.Diagnostics, "\n")}
+				suite.Failures++
+			}
+			suite.Cases = append(suite.Cases, testCase)
+		}
+		if problem := result.problem(); problem != "" {
+			suite.Error = &junitFailure{Message: problem}
+			suite.Errors++
+		}
+
+		suites.Tests += suite.Tests
+		suites.Failures += suite.Failures + suite.Errors
+		suites.Suites = append(suites.Suites, suite)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(suites); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
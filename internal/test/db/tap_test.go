@@ -0,0 +1,80 @@
+package db
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseTap(t *testing.T) {
+	t.Run("passing file", func(t *testing.T) {
+		result := parseTap("a.sql", []string{"1..2", "ok 1 - table exists", "ok 2"})
+		if result.failed() || result.Plan != 2 || len(result.Tests) != 2 || result.Tests[0].Description != "table exists" {
+			t.Errorf("unexpected result: %+v", result)
+		}
+	})
+
+	t.Run("failing test with diagnostics", func(t *testing.T) {
+		result := parseTap("a.sql", []string{"1..2", "ok 1", "not ok 2 - counts match", "# Failed test 2", "#   have: 1", "#   want: 2"})
+		if !result.failed() || len(result.Tests[1].Diagnostics) != 3 || result.Tests[1].Diagnostics[1] != "have: 1" {
+			t.Errorf("unexpected result: %+v", result)
+		}
+	})
+
+	t.Run("failing todo test", func(t *testing.T) {
+		if result := parseTap("a.sql", []string{"1..1", "not ok 1 - later # TODO not yet"}); result.failed() {
+			t.Errorf("unexpected result: %+v", result)
+		}
+	})
+
+	t.Run("plan mismatch", func(t *testing.T) {
+		if result := parseTap("a.sql", []string{"1..3", "ok 1", "ok 2"}); !result.failed() {
+			t.Errorf("unexpected result: %+v", result)
+		}
+	})
+}
+
+func TestWriteReports(t *testing.T) {
+	results := []fileResult{
+		parseTap("tests/a.sql", []string{"1..1", "ok 1 - works"}),
+		parseTap("tests/b.sql", []string{"1..1", "not ok 1 - broken", "# Failed test 1"}),
+		{Path: "tests/c.sql", Plan: -1, Err: errors.New("Error running tests/c.sql:3: syntax error")},
+	}
+
+	var tap bytes.Buffer
+	writeTap(&tap, results)
+	expected := `TAP version 13
+1..3
+# Subtest: tests/a.sql
+    1..1
+    ok 1 - works
+ok 1 - tests/a.sql
+# Subtest: tests/b.sql
+    1..1
+    not ok 1 - broken
+    # Failed test 1
+not ok 2 - tests/b.sql
+# Subtest: tests/c.sql
+not ok 3 - tests/c.sql
+# Error running tests/c.sql:3: syntax error
+`
+	if tap.String() != expected {
+		t.Errorf("unexpected TAP:\n%s", tap.String())
+	}
+
+	var junit bytes.Buffer
+	if err := writeJunit(&junit, results); err != nil {
+		t.Fatal(err)
+	}
+	for _, fragment := range []string{
+		`<testsuites tests="2" failures="2">`,
+		`<testcase name="1 - broken" classname="tests/b.sql">`,
+		`<failure message="not ok">Failed test 1</failure>`,
+		`<error message="Error running tests/c.sql:3: syntax error">`,
+	} {
+		if !strings.Contains(junit.String(), fragment) {
+			t.Errorf("missing %q in JUnit XML:\n%s", fragment, junit.String())
+		}
+	}
+}
@@ -13,3 +13,13 @@ func Aqua(str string) string {
 func Bold(str string) string {
 	return lipgloss.NewStyle().Bold(true).Render(str)
 }
+
+// For errors.
+func Red(str string) string {
+	return lipgloss.NewStyle().Foreground(lipgloss.Color("9")).Render(str)
+}
+
+// For warnings.
+func Yellow(str string) string {
+	return lipgloss.NewStyle().Foreground(lipgloss.Color("11")).Render(str)
+}
@@ -9,6 +9,7 @@ import (
 	"path/filepath"
 	"regexp"
 	"strconv"
+	"strings"
 	"text/template"
 
 	"github.com/BurntSushi/toml"
@@ -16,7 +17,6 @@ import (
 )
 
 var (
-	DbImage     string
 	NetId       string
 	DbId        string
 	KongId      string
@@ -25,7 +25,6 @@ var (
 	RealtimeId  string
 	RestId      string
 	StorageId   string
-	DifferId    string
 	PgmetaId    string
 	StudioId    string
 	DenoRelayId string
@@ -64,15 +63,21 @@ var Config config
 
 type (
 	config struct {
-		ProjectId string `toml:"project_id"`
-		Api       api
-		Db        db
-		Studio    studio
-		Inbucket  inbucket
-		Auth      auth
+		ProjectId     string `toml:"project_id"`
+		Api           api
+		Db            db
+		Studio        studio
+		Inbucket      inbucket
+		Auth          auth
+		Kong          kong
+		Postgrest     postgrest
+		Realtime      realtime
+		Storage       storage
+		Pgmeta        pgmeta
 		Edgefunctions edgefunctions
-		// TODO
-		// Scripts   scripts
+		Functions     map[string]function
+		Scripts       scripts
+		Images        images
 	}
 
 	api struct {
@@ -85,20 +90,82 @@ type (
 	db struct {
 		Port         uint
 		MajorVersion uint `toml:"major_version"`
+		Seed         seed
+		Lint         lint
+	}
+
+	seed struct {
+		Files    []string
+		Csv      []csvSeed
+		Branches map[string]branchSeed
+	}
+
+	// Fields left unset fall back to the ones in `[db.seed]`.
+	branchSeed struct {
+		Files []string
+		Csv   []csvSeed
+	}
+
+	csvSeed struct {
+		File  string
+		Table string
+	}
+
+	lint struct {
+		// Rule ID to severity: "error", "warning" or "off".
+		Rules map[string]string
+	}
+
+	// Image overrides. Either a tag, or a full image name.
+	images struct {
+		Db        string
+		Kong      string
+		Gotrue    string
+		Inbucket  string
+		Realtime  string
+		Postgrest string
+		Storage   string
+		Pgmeta    string
+		Studio    string
+		DenoRelay string `toml:"deno_relay"`
 	}
 
 	studio struct {
-		Port uint
+		Enabled *bool
+		Port    uint
 	}
 
 	inbucket struct {
-		Port uint
+		Enabled *bool
+		Port    uint
+	}
+
+	kong struct {
+		Enabled *bool
+	}
+
+	postgrest struct {
+		Enabled *bool
+	}
+
+	realtime struct {
+		Enabled *bool
+	}
+
+	storage struct {
+		Enabled *bool
+	}
+
+	pgmeta struct {
+		Enabled *bool
 	}
 
 	auth struct {
+		Enabled                *bool
 		SiteUrl                string   `toml:"site_url"`
 		AdditionalRedirectUrls []string `toml:"additional_redirect_urls"`
 		JwtExpiry              uint     `toml:"jwt_expiry"`
+		JwtSecret              string   `toml:"jwt_secret"`
 		EnableSignup           *bool    `toml:"enable_signup"`
 		Email                  email
 		External               map[string]provider
@@ -117,18 +184,29 @@ type (
 	}
 
 	edgefunctions struct {
-		SrcPath   string `toml:"src_path"`
-		FunctionsPath   string `toml:"functions_path"`
+		SrcPath       string `toml:"src_path"`
+		FunctionsPath string `toml:"functions_path"`
 	}
 
-	// TODO
-	// scripts struct {
-	// 	BeforeMigrations string `toml:"before_migrations"`
-	// 	AfterMigrations  string `toml:"after_migrations"`
-	// }
+	// Paths are relative to the Function's folder.
+	function struct {
+		VerifyJWT  *bool    `toml:"verify_jwt"`
+		ImportMap  string   `toml:"import_map"`
+		Entrypoint string   `toml:"entrypoint"`
+		EnvFile    string   `toml:"env_file"`
+		Secrets    []string `toml:"secrets"`
+	}
+
+	scripts struct {
+		BeforeMigrations []string `toml:"before_migrations"`
+		AfterMigrations  []string `toml:"after_migrations"`
+	}
 )
 
 func LoadConfig() error {
+	// Reset so that keys removed from supabase.toml fall back to defaults.
+	Config = config{}
+
 	if _, err := toml.DecodeFile("supabase.toml", &Config); err == nil {
 		// skip
 	} else if errors.Is(err, os.ErrNotExist) {
@@ -159,7 +237,6 @@ func LoadConfig() error {
 			RealtimeId = "supabase_realtime_" + Config.ProjectId
 			RestId = "supabase_rest_" + Config.ProjectId
 			StorageId = "supabase_storage_" + Config.ProjectId
-			DifferId = "supabase_differ_" + Config.ProjectId
 			PgmetaId = "supabase_pg_meta_" + Config.ProjectId
 			StudioId = "supabase_studio_" + Config.ProjectId
 			DenoRelayId = "supabase_deno_relay_" + Config.ProjectId
@@ -173,20 +250,46 @@ func LoadConfig() error {
 		if Config.Db.Port == 0 {
 			return errors.New("Missing required field in config: db.port")
 		}
+		var defaultDbImage string
 		switch Config.Db.MajorVersion {
 		case 0:
 			return errors.New("Missing required field in config: db.major_version")
 		case 12:
 			return errors.New("Postgres version 12.x is unsupported. To use the CLI, either start a new project or follow project migration steps here: https://supabase.com/docs/guides/database#migrating-between-projects.")
 		case 13:
-			DbImage = "supabase/postgres:13.3.0"
+			defaultDbImage = "supabase/postgres:13.3.0"
 			InitialSchemaSql = initialSchemaPg13Sql
 		case 14:
-			DbImage = "supabase/postgres:14.1.0.21"
+			defaultDbImage = "supabase/postgres:14.1.0.21"
 			InitialSchemaSql = initialSchemaPg14Sql
 		default:
 			return fmt.Errorf("Failed reading config: Invalid %s: %v.", Aqua("db.major_version"), Config.Db.MajorVersion)
 		}
+		if err := loadImages(defaultDbImage); err != nil {
+			return err
+		}
+		if Config.Db.Seed.Files == nil {
+			Config.Db.Seed.Files = []string{"seed.sql"}
+		}
+		for _, csv := range Config.Db.Seed.Csv {
+			if err := validateCsvSeed(csv, "db.seed.csv"); err != nil {
+				return err
+			}
+		}
+		for branch, branchSeed := range Config.Db.Seed.Branches {
+			for _, csv := range branchSeed.Csv {
+				if err := validateCsvSeed(csv, "db.seed.branches."+branch+".csv"); err != nil {
+					return err
+				}
+			}
+		}
+		for rule, severity := range Config.Db.Lint.Rules {
+			switch severity {
+			case "error", "warning", "off":
+			default:
+				return fmt.Errorf("Invalid config: db.lint.rules.%s must be one of error, warning or off.", rule)
+			}
+		}
 		if Config.Studio.Port == 0 {
 			return errors.New("Missing required field in config: studio.port")
 		}
@@ -199,6 +302,53 @@ func LoadConfig() error {
 		if Config.Auth.JwtExpiry == 0 {
 			Config.Auth.JwtExpiry = 3600
 		}
+		if Config.Auth.JwtSecret == "" {
+			Config.Auth.JwtSecret = defaultJwtSecret
+		} else {
+			v, err := maybeLoadEnv(Config.Auth.JwtSecret)
+			if err != nil {
+				return err
+			}
+			Config.Auth.JwtSecret = v
+		}
+		if len(Config.Auth.JwtSecret) < 32 {
+			return errors.New("Invalid config: auth.jwt_secret must be at least 32 characters long.")
+		}
+		if err := mintApiKeys(Config.Auth.JwtSecret); err != nil {
+			return err
+		}
+		if Config.Studio.Enabled == nil {
+			x := true
+			Config.Studio.Enabled = &x
+		}
+		if Config.Inbucket.Enabled == nil {
+			x := true
+			Config.Inbucket.Enabled = &x
+		}
+		if Config.Kong.Enabled == nil {
+			x := true
+			Config.Kong.Enabled = &x
+		}
+		if Config.Postgrest.Enabled == nil {
+			x := true
+			Config.Postgrest.Enabled = &x
+		}
+		if Config.Pgmeta.Enabled == nil {
+			x := true
+			Config.Pgmeta.Enabled = &x
+		}
+		if Config.Realtime.Enabled == nil {
+			x := true
+			Config.Realtime.Enabled = &x
+		}
+		if Config.Storage.Enabled == nil {
+			x := true
+			Config.Storage.Enabled = &x
+		}
+		if Config.Auth.Enabled == nil {
+			x := true
+			Config.Auth.Enabled = &x
+		}
 		if Config.Auth.EnableSignup == nil {
 			x := true
 			Config.Auth.EnableSignup = &x
@@ -235,27 +385,66 @@ func LoadConfig() error {
 		if Config.Edgefunctions.FunctionsPath == "" {
 			Config.Edgefunctions.FunctionsPath = ".supabase/functions"
 		}
+		for slug, function := range Config.Functions {
+			if err := ValidateFunctionSlug(slug); err != nil {
+				return fmt.Errorf("Invalid config for functions.%s: %w", slug, err)
+			}
+			if entrypoint := filepath.Clean(function.Entrypoint); filepath.IsAbs(entrypoint) || entrypoint == ".." || strings.HasPrefix(entrypoint, ".."+string(filepath.Separator)) {
+				return errors.New("Invalid config for functions." + slug + ": entrypoint must be inside the Function's folder.")
+			}
+			Config.Functions[slug] = function.withDefaults()
+		}
 	}
 
 	return nil
 }
 
-func InterpolateEnvInConfig() error {
-	maybeLoadEnv := func(s string) (string, error) {
-		matches := regexp.MustCompile(`^env\((.*)\)$`).FindStringSubmatch(s)
-		if len(matches) == 0 {
-			return s, nil
-		}
+// GetFunctionConfig returns the `[functions.<slug>]` settings of the Function,
+// or the defaults if it has none. Must be called after LoadConfig.
+func GetFunctionConfig(slug string) function {
+	if function, ok := Config.Functions[slug]; ok {
+		return function
+	}
+	return function{}.withDefaults()
+}
 
-		envName := matches[1]
-		value := os.Getenv(envName)
-		if value == "" {
-			return "", errors.New(`Error evaluating "env(` + envName + `)": environment variable ` + envName + " is unset.")
-		}
+func (f function) withDefaults() function {
+	if f.VerifyJWT == nil {
+		x := true
+		f.VerifyJWT = &x
+	}
+	if f.Entrypoint == "" {
+		f.Entrypoint = "index.ts"
+	}
+	return f
+}
+
+func validateCsvSeed(csv csvSeed, key string) error {
+	if csv.File == "" {
+		return errors.New("Missing required field in config: " + key + ".file")
+	}
+	if csv.Table == "" {
+		return errors.New("Missing required field in config: " + key + ".table")
+	}
+	return nil
+}
+
+func maybeLoadEnv(s string) (string, error) {
+	matches := regexp.MustCompile(`^env\((.*)\)$`).FindStringSubmatch(s)
+	if len(matches) == 0 {
+		return s, nil
+	}
 
-		return value, nil
+	envName := matches[1]
+	value := os.Getenv(envName)
+	if value == "" {
+		return "", errors.New(`Error evaluating "env(` + envName + `)": environment variable ` + envName + " is unset.")
 	}
 
+	return value, nil
+}
+
+func InterpolateEnvInConfig() error {
 	for _, ext := range authExternalProviders {
 		if Config.Auth.External[ext].Enabled {
 			var clientId, secret string
@@ -414,9 +603,14 @@ func WriteConfig(test bool) error {
 		tmpl = initConfigTemplate
 	}
 
+	jwtSecret, err := generateJwtSecret()
+	if err != nil {
+		return err
+	}
+
 	if err := tmpl.Execute(
 		&initConfigBuf,
-		struct{ ProjectId string }{ProjectId: dir},
+		struct{ ProjectId, JwtSecret string }{ProjectId: dir, JwtSecret: jwtSecret},
 	); err != nil {
 		return err
 	}
@@ -10,6 +10,7 @@ import (
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/client"
+	"github.com/docker/go-connections/nat"
 )
 
 var Docker = func() *client.Client {
@@ -43,10 +44,50 @@ func DockerExec(ctx context.Context, container string, cmd []string) (io.Reader,
 	if err != nil {
 		return nil, err
 	}
+	closeOnCancel(ctx, resp)
 
 	return resp.Reader, nil
 }
 
+// DockerExecWithStdin is like DockerExec, but also streams stdin to the
+// command. Useful for inputs too large to pass as an argument, e.g. dumps.
+func DockerExecWithStdin(ctx context.Context, container string, cmd []string, stdin io.Reader) (io.Reader, error) {
+	exec, err := Docker.ContainerExecCreate(
+		ctx,
+		container,
+		types.ExecConfig{Cmd: cmd, AttachStdin: true, AttachStderr: true, AttachStdout: true},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := Docker.ContainerExecAttach(ctx, exec.ID, types.ExecStartCheck{})
+	if err != nil {
+		return nil, err
+	}
+	closeOnCancel(ctx, resp)
+
+	go func() {
+		_, _ = io.Copy(resp.Conn, stdin)
+		_ = resp.CloseWrite()
+	}()
+
+	return resp.Reader, nil
+}
+
+// closeOnCancel closes a hijacked connection once ctx is cancelled. Reads of a
+// container's output otherwise block until the command exits, even after e.g.
+// SIGINT.
+func closeOnCancel(ctx context.Context, resp types.HijackedResponse) {
+	if ctx.Done() == nil {
+		return
+	}
+	go func() {
+		<-ctx.Done()
+		resp.Close()
+	}()
+}
+
 // NOTE: There's a risk of data race with reads & writes from `DockerRun` and
 // reads from `DockerRemoveAll`, but since they're expected to be run on the
 // same thread, this is fine.
@@ -70,6 +111,7 @@ func DockerRun(
 		fmt.Fprintln(os.Stderr, "Failed DockerContainerAttach:", err)
 		return nil, err
 	}
+	closeOnCancel(ctx, resp)
 
 	if err := Docker.ContainerStart(ctx, container.ID, types.ContainerStartOptions{}); err != nil {
 		fmt.Fprintln(os.Stderr, "Failed DockerContainerStart:", err)
@@ -101,3 +143,26 @@ func DockerRemoveAll() {
 
 	wg.Wait()
 }
+
+// DockerGetHostPort returns the host port that a port of a running container is
+// published on. Useful for containers started with a random host port.
+func DockerGetHostPort(ctx context.Context, container string, port nat.Port) (string, error) {
+	resp, err := Docker.ContainerInspect(ctx, container)
+	if err != nil {
+		return "", err
+	}
+
+	bindings := resp.NetworkSettings.Ports[port]
+	if len(bindings) == 0 {
+		return "", fmt.Errorf("Port %s of container %s is not published.", port, container)
+	}
+
+	return bindings[0].HostPort, nil
+}
+
+// DockerContainerExists returns whether the container was created, e.g. by
+// `supabase start`, which may leave out some services.
+func DockerContainerExists(ctx context.Context, container string) bool {
+	_, err := Docker.ContainerInspect(ctx, container)
+	return err == nil
+}
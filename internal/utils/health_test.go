@@ -0,0 +1,32 @@
+package utils
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestProbeHttp(t *testing.T) {
+	for status, healthy := range map[int]bool{
+		http.StatusOK:                 true,
+		http.StatusNotFound:           true,
+		http.StatusUnauthorized:       true,
+		http.StatusBadGateway:         false,
+		http.StatusServiceUnavailable: false,
+	} {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("apikey") != AnonKey {
+				t.Errorf("missing apikey header")
+			}
+			w.WriteHeader(status)
+		}))
+		err := probeHttp(server.URL)(context.Background())
+		server.Close()
+		if healthy && err != nil {
+			t.Errorf("%d: expected healthy, got %v", status, err)
+		} else if !healthy && err == nil {
+			t.Errorf("%d: expected unhealthy", status)
+		}
+	}
+}
@@ -0,0 +1,224 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/docker/docker/api/types"
+)
+
+const DefaultRegistry = "docker.io"
+
+// Images used by the local development setup, without the registry. Set by
+// LoadConfig from the defaults and the overrides in `[images]`.
+var (
+	DbImage        string
+	KongImage      string
+	GotrueImage    string
+	InbucketImage  string
+	RealtimeImage  string
+	PostgrestImage string
+	StorageImage   string
+	PgmetaImage    string
+	StudioImage    string
+	DenoRelayImage string
+)
+
+// Warnings about image overrides are shown once, even though LoadConfig may
+// run more than once.
+var imageWarningsShown bool
+
+// Set from the global `--registry` flag. Takes precedence over the
+// SUPABASE_REGISTRY environment variable.
+var RegistryFlag string
+
+// GetRegistry returns the registry images are pulled from, without a trailing
+// slash. A mirror can be given with a path prefix, e.g.
+// `mirror.example.com/dockerhub`.
+func GetRegistry() string {
+	if RegistryFlag != "" {
+		return strings.TrimSuffix(RegistryFlag, "/")
+	}
+	if registry := os.Getenv("SUPABASE_REGISTRY"); registry != "" {
+		return strings.TrimSuffix(registry, "/")
+	}
+	return DefaultRegistry
+}
+
+// GetRegistryImageUrl returns the reference to pull and run an image from,
+// e.g. `docker.io/supabase/gotrue:v2.6.18`. Images that name their own
+// registry, e.g. `ghcr.io/acme/gotrue:v2` from `[images]`, are used as is.
+func GetRegistryImageUrl(image string) string {
+	if hasRegistry(image) {
+		return image
+	}
+	return GetRegistry() + "/" + image
+}
+
+// hasRegistry reports whether the first path component of the image is a
+// registry host, the way Docker tells them apart from Docker Hub namespaces.
+func hasRegistry(image string) bool {
+	i := strings.Index(image, "/")
+	if i < 0 {
+		return false
+	}
+	host := image[:i]
+	return strings.ContainsAny(host, ".:") || host == "localhost"
+}
+
+// GetServiceImages returns the images used by `supabase start` and
+// `supabase functions serve`. Must be called after LoadConfig, which picks
+// DbImage.
+func GetServiceImages() []string {
+	return []string{
+		DbImage,
+		KongImage,
+		GotrueImage,
+		InbucketImage,
+		RealtimeImage,
+		PostgrestImage,
+		StorageImage,
+		PgmetaImage,
+		StudioImage,
+		DenoRelayImage,
+	}
+}
+
+// PullImages pulls the images that aren't available locally yet, all at once,
+// reporting their combined download progress to p. Duplicates are pulled once.
+func PullImages(ctx context.Context, p Program, images []string) error {
+	missing := []string{}
+	seen := map[string]bool{}
+	for _, image := range images {
+		url := GetRegistryImageUrl(image)
+		if seen[url] {
+			continue
+		}
+		seen[url] = true
+
+		if _, _, err := Docker.ImageInspectWithRaw(ctx, url); err != nil {
+			missing = append(missing, url)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	p.Send(StatusMsg(fmt.Sprintf("Pulling %d images...", len(missing))))
+
+	pp := newPullProgress()
+	errs := make([]error, len(missing))
+	var wg sync.WaitGroup
+	for i, url := range missing {
+		wg.Add(1)
+		go func(i int, url string) {
+			defer wg.Done()
+
+			out, err := Docker.ImagePull(ctx, url, types.ImagePullOptions{})
+			if err != nil {
+				errs[i] = fmt.Errorf("Error pulling %s: %w", url, err)
+				return
+			}
+			defer out.Close()
+
+			if err := processPullOutput(out, p, pp); err != nil {
+				errs[i] = fmt.Errorf("Error pulling %s: %w", url, err)
+			}
+		}(i, url)
+	}
+	wg.Wait()
+
+	p.Send(ProgressMsg(nil))
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadImages sets the images from their defaults and `[images]`. The initial
+// schema is picked by `db.major_version`, so a db image of another major
+// version is an error. Overriding a service whose schema is part of the
+// initial schema, or using `latest`, only warns.
+func loadImages(defaultDbImage string) error {
+	images := []struct {
+		key           string
+		image         *string
+		defaultImage  string
+		override      string
+		initialSchema bool
+	}{
+		{"db", &DbImage, defaultDbImage, Config.Images.Db, false},
+		{"kong", &KongImage, defaultKongImage, Config.Images.Kong, false},
+		{"gotrue", &GotrueImage, defaultGotrueImage, Config.Images.Gotrue, true},
+		{"inbucket", &InbucketImage, defaultInbucketImage, Config.Images.Inbucket, false},
+		{"realtime", &RealtimeImage, defaultRealtimeImage, Config.Images.Realtime, true},
+		{"postgrest", &PostgrestImage, defaultPostgrestImage, Config.Images.Postgrest, false},
+		{"storage", &StorageImage, defaultStorageImage, Config.Images.Storage, true},
+		{"pgmeta", &PgmetaImage, defaultPgmetaImage, Config.Images.Pgmeta, false},
+		{"studio", &StudioImage, defaultStudioImage, Config.Images.Studio, false},
+		{"deno_relay", &DenoRelayImage, defaultDenoRelayImage, Config.Images.DenoRelay, false},
+	}
+
+	warnings := []string{}
+	for _, i := range images {
+		*i.image = overrideImage(i.defaultImage, i.override)
+		if i.override == "" || *i.image == i.defaultImage {
+			continue
+		}
+
+		if i.key == "db" {
+			majorVersion := strconv.FormatUint(uint64(Config.Db.MajorVersion), 10)
+			if !strings.HasPrefix(imageTag(DbImage), majorVersion+".") {
+				return fmt.Errorf("Invalid config: images.db %s doesn't match db.major_version %s. The tag must start with %s.", Aqua(DbImage), majorVersion, Aqua(majorVersion+"."))
+			}
+		}
+		if imageTag(*i.image) == "latest" {
+			warnings = append(warnings, "WARNING: images."+i.key+" uses the latest tag, so it may change between runs. Pin a version to match your hosted project.")
+		}
+		if i.initialSchema {
+			warnings = append(warnings, "WARNING: images."+i.key+" is "+*i.image+", but the initial schema was generated for "+i.defaultImage+". The service may fail to start if their schemas differ.")
+		}
+	}
+
+	if !imageWarningsShown {
+		for _, warning := range warnings {
+			fmt.Fprintln(os.Stderr, warning)
+		}
+		imageWarningsShown = true
+	}
+
+	return nil
+}
+
+// overrideImage returns the image for an `[images]` entry: a full image name,
+// or a tag of the default image.
+func overrideImage(defaultImage string, override string) string {
+	if override == "" {
+		return defaultImage
+	}
+	if strings.ContainsAny(override, ":/") {
+		return override
+	}
+	return imageRepository(defaultImage) + ":" + override
+}
+
+func imageRepository(image string) string {
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		return image[:i]
+	}
+	return image
+}
+
+func imageTag(image string) string {
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		return image[i+1:]
+	}
+	return "latest"
+}
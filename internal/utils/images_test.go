@@ -0,0 +1,27 @@
+package utils
+
+import "testing"
+
+func TestGetRegistryImageUrl(t *testing.T) {
+	if url := GetRegistryImageUrl("library/kong:2.1"); url != "docker.io/library/kong:2.1" {
+		t.Errorf("unexpected image URL: %s", url)
+	}
+
+	t.Setenv("SUPABASE_REGISTRY", "mirror.example.com/dockerhub/")
+	if url := GetRegistryImageUrl("library/kong:2.1"); url != "mirror.example.com/dockerhub/library/kong:2.1" {
+		t.Errorf("unexpected image URL: %s", url)
+	}
+
+	RegistryFlag = "localhost:5000"
+	t.Cleanup(func() { RegistryFlag = "" })
+	if url := GetRegistryImageUrl("library/kong:2.1"); url != "localhost:5000/library/kong:2.1" {
+		t.Errorf("unexpected image URL: %s", url)
+	}
+
+	// Overrides in `[images]` may name their own registry.
+	for _, image := range []string{"ghcr.io/acme/gotrue:v2", "localhost/gotrue:dev", "registry:5000/acme/gotrue:v2"} {
+		if url := GetRegistryImageUrl(image); url != image {
+			t.Errorf("expected %s to be used as is, got %s", image, url)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFunctionDiscovery(t *testing.T) {
+	t.Cleanup(func() { Config = config{} })
+	dir := t.TempDir()
+	Config.Edgefunctions.SrcPath = dir
+	Config.Edgefunctions.FunctionsPath = "functions"
+	for _, name := range []string{"hello", "world", SharedFunctionsDir} {
+		if err := os.MkdirAll(filepath.Join(dir, "functions", name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "functions", name, "index.ts"), []byte{}, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	t.Run("skips the shared folder", func(t *testing.T) {
+		slugs, err := ListFunctionSlugs()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(slugs) != 2 || slugs[0] != "hello" || slugs[1] != "world" {
+			t.Errorf("unexpected slugs: %v", slugs)
+		}
+	})
+
+	t.Run("resolves import maps", func(t *testing.T) {
+		if importMap := GetFunctionImportMap("hello"); importMap != "" {
+			t.Errorf("expected no import map, got %s", importMap)
+		}
+
+		global := filepath.Join(dir, "functions", ImportMapFile)
+		if err := os.WriteFile(global, []byte("{}"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		local := filepath.Join(dir, "functions", "world", ImportMapFile)
+		if err := os.WriteFile(local, []byte("{}"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if importMap := GetFunctionImportMap("hello"); importMap != global {
+			t.Errorf("expected %s, got %s", global, importMap)
+		}
+		if importMap := GetFunctionImportMap("world"); importMap != local {
+			t.Errorf("expected %s, got %s", local, importMap)
+		}
+
+		Config.Functions = map[string]function{"hello": {ImportMap: "../import_map.prod.json"}}
+		if importMap := GetFunctionImportMap("hello"); importMap != filepath.Join(dir, "functions", "import_map.prod.json") {
+			t.Errorf("expected the configured import map, got %s", importMap)
+		}
+	})
+}
@@ -0,0 +1,98 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	OutputPretty = "pretty"
+	OutputJson   = "json"
+	OutputYaml   = "yaml"
+	OutputEnv    = "env"
+	// Only supported by `db lint`.
+	OutputSarif = "sarif"
+)
+
+// Set by the global `--output` flag.
+var OutputFormat = EnumFlag{
+	Allowed: []string{OutputPretty, OutputJson, OutputYaml, OutputEnv, OutputSarif},
+	Value:   OutputPretty,
+}
+
+// EnumFlag is a pflag.Value that only accepts one of the allowed values.
+type EnumFlag struct {
+	Allowed []string
+	Value   string
+}
+
+func (f *EnumFlag) String() string {
+	return f.Value
+}
+
+func (f *EnumFlag) Set(value string) error {
+	for _, allowed := range f.Allowed {
+		if value == allowed {
+			f.Value = value
+			return nil
+		}
+	}
+
+	return fmt.Errorf("must be one of [ %s ]", strings.Join(f.Allowed, " | "))
+}
+
+func (f *EnumFlag) Type() string {
+	return "[ " + strings.Join(f.Allowed, " | ") + " ]"
+}
+
+// EncodeOutput writes value in a machine-readable format. Fields are named
+// after their `json` tags in all formats. The env format only supports
+// map[string]string values, which are written as shell-quoted KEY=value lines.
+func EncodeOutput(format string, w io.Writer, value interface{}) error {
+	switch format {
+	case OutputEnv:
+		env, ok := value.(map[string]string)
+		if !ok {
+			return errors.New("--output " + OutputEnv + " is not supported by this command.")
+		}
+
+		keys := make([]string, 0, len(env))
+		for key := range env {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			if _, err := fmt.Fprintf(w, "%s='%s'\n", key, strings.ReplaceAll(env[key], "'", `'\''`)); err != nil {
+				return err
+			}
+		}
+	case OutputJson:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(value)
+	case OutputYaml:
+		// Round-trip through JSON so `json` tags apply.
+		jsonBytes, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		var generic interface{}
+		if err := json.Unmarshal(jsonBytes, &generic); err != nil {
+			return err
+		}
+		return yaml.NewEncoder(w).Encode(generic)
+	case OutputSarif:
+		return errors.New("--output " + OutputSarif + " is not supported by this command.")
+	default:
+		return errors.New("Unsupported output format: " + format)
+	}
+
+	return nil
+}
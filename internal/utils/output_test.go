@@ -0,0 +1,48 @@
+package utils
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestEncodeOutput(t *testing.T) {
+	t.Run("env output is shell-quoted and sorted", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := EncodeOutput(OutputEnv, &buf, map[string]string{"B": "it's", "A": "$HOME"}); err != nil {
+			t.Fatal(err)
+		}
+		if expected := "A='$HOME'\nB='it'\\''s'\n"; buf.String() != expected {
+			t.Errorf("expected %q, got %q", expected, buf.String())
+		}
+	})
+
+	t.Run("env output rejects lists", func(t *testing.T) {
+		if err := EncodeOutput(OutputEnv, &bytes.Buffer{}, []string{"a"}); err == nil {
+			t.Error("expected error")
+		}
+	})
+
+	t.Run("yaml output uses json field names", func(t *testing.T) {
+		var buf bytes.Buffer
+		value := []struct {
+			Name    string `json:"name"`
+			Current bool   `json:"current"`
+		}{{Name: "main", Current: true}}
+		if err := EncodeOutput(OutputYaml, &buf, value); err != nil {
+			t.Fatal(err)
+		}
+		if expected := "- current: true\n  name: main\n"; buf.String() != expected {
+			t.Errorf("expected %q, got %q", expected, buf.String())
+		}
+	})
+
+	t.Run("flag rejects unknown formats", func(t *testing.T) {
+		flag := EnumFlag{Allowed: []string{OutputPretty, OutputJson}, Value: OutputPretty}
+		if err := flag.Set("xml"); err == nil {
+			t.Error("expected error")
+		}
+		if err := flag.Set(OutputJson); err != nil || flag.Value != OutputJson {
+			t.Errorf("expected %s, got %s (%v)", OutputJson, flag.Value, err)
+		}
+	})
+}
@@ -0,0 +1,142 @@
+package utils
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/jackc/pgconn"
+	pgx "github.com/jackc/pgx/v4"
+)
+
+const (
+	BeforeMigrations = "before_migrations"
+	AfterMigrations  = "after_migrations"
+)
+
+// RunScripts runs the `[scripts]` hook of the given name against the database
+// at url. Entries ending in `.sql` are files relative to `.supabase`, applied
+// in a transaction. Other entries are shell commands run from the project
+// root, with the database URL in `SUPABASE_DB_URL`. Output is sent to p as
+// PsqlMsg, or printed if p is nil. The first failing entry aborts.
+func RunScripts(ctx context.Context, p Program, hook string, url string) error {
+	var entries []string
+	switch hook {
+	case BeforeMigrations:
+		entries = Config.Scripts.BeforeMigrations
+	case AfterMigrations:
+		entries = Config.Scripts.AfterMigrations
+	default:
+		return fmt.Errorf("Unknown script hook: %s", hook)
+	}
+
+	send := func(msg interface{}) {
+		if p != nil {
+			p.Send(msg)
+		} else if line, ok := msg.(PsqlMsg); ok && line != nil {
+			fmt.Println(*line)
+		} else if status, ok := msg.(StatusMsg); ok {
+			fmt.Println(status)
+		}
+	}
+
+	for _, entry := range entries {
+		send(StatusMsg("Running " + hook + " script " + Bold(entry) + "..."))
+
+		var err error
+		if strings.HasSuffix(entry, ".sql") {
+			err = runSqlScript(ctx, send, filepath.Join(".supabase", entry), url)
+		} else {
+			err = runShellScript(ctx, send, entry, url)
+		}
+		send(PsqlMsg(nil))
+		if err != nil {
+			return fmt.Errorf("Error running %s script %s: %w", hook, Aqua(entry), err)
+		}
+	}
+
+	return nil
+}
+
+func runSqlScript(ctx context.Context, send func(interface{}), path string, url string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	config, err := pgx.ParseConfig(url)
+	if err != nil {
+		return err
+	}
+	config.OnNotice = func(_ *pgconn.PgConn, notice *pgconn.Notice) {
+		line := notice.Severity + ":  " + notice.Message
+		send(PsqlMsg(&line))
+	}
+
+	conn, err := pgx.ConnectConfig(ctx, config)
+	if err != nil {
+		return err
+	}
+	defer conn.Close(context.Background())
+
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(context.Background()) //nolint:errcheck
+
+	if _, err := tx.Exec(ctx, string(content)); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func runShellScript(ctx context.Context, send func(interface{}), command string, url string) error {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	cmd.Env = append(os.Environ(), "SUPABASE_DB_URL="+url)
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	for _, r := range []io.Reader{stdout, stderr} {
+		wg.Add(1)
+		go func(r io.Reader) {
+			defer wg.Done()
+			scanner := bufio.NewScanner(r)
+			for scanner.Scan() {
+				line := scanner.Text()
+				mu.Lock()
+				send(PsqlMsg(&line))
+				mu.Unlock()
+			}
+		}(r)
+	}
+	// Pipes must be drained before Wait closes them.
+	wg.Wait()
+
+	return cmd.Wait()
+}
@@ -0,0 +1,40 @@
+package utils
+
+import (
+	"context"
+	"runtime"
+	"testing"
+)
+
+func TestRunScripts(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	t.Cleanup(func() { Config = config{} })
+
+	t.Run("streams shell command output", func(t *testing.T) {
+		Config.Scripts.BeforeMigrations = []string{`echo "$SUPABASE_DB_URL"`}
+
+		var lines []string
+		send := func(msg interface{}) {
+			if line, ok := msg.(PsqlMsg); ok && line != nil {
+				lines = append(lines, *line)
+			}
+		}
+		if err := runShellScript(context.Background(), send, Config.Scripts.BeforeMigrations[0], "postgresql://localhost/postgres"); err != nil {
+			t.Fatal(err)
+		}
+		if len(lines) != 1 || lines[0] != "postgresql://localhost/postgres" {
+			t.Errorf("unexpected output: %v", lines)
+		}
+	})
+
+	t.Run("aborts on the first failure", func(t *testing.T) {
+		Config.Scripts.AfterMigrations = []string{"exit 3", "exit 0"}
+
+		err := RunScripts(context.Background(), nil, AfterMigrations, "postgresql://localhost/postgres")
+		if err == nil {
+			t.Fatal("expected to fail")
+		}
+	})
+}
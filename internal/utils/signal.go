@@ -0,0 +1,33 @@
+package utils
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+// CancelOnSignal calls cancel on the first SIGINT or SIGTERM, so long-running
+// commands clean up the same way as on Ctrl+C in the TUI, which swallows
+// SIGINT. A second signal kills the process as usual, e.g. if cleanup hangs.
+// Call the returned func to stop catching signals.
+func CancelOnSignal(cancel context.CancelFunc) func() {
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-sigCh:
+			cancel()
+		case <-done:
+		}
+		signal.Stop(sigCh)
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { close(done) })
+	}
+}
@@ -0,0 +1,24 @@
+package utils
+
+import (
+	"context"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestCancelOnSignal(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	stop := CancelOnSignal(cancel)
+	defer stop()
+
+	if err := syscall.Kill(syscall.Getpid(), syscall.SIGTERM); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("context was not cancelled on SIGTERM")
+	}
+}